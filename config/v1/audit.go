@@ -0,0 +1,116 @@
+package config
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/raf555/salome/melt/log"
+)
+
+// ChangeEvent records a change of the config fetched by [Dynamic].
+// Values are never included, only their keyed hashes if enabled with [WithAuditHashKey],
+// so events can be stored and shipped safely.
+type ChangeEvent struct {
+	// Generation is incremented on every change. The initial config is generation 0.
+	Generation uint64    `json:"generation"`
+	Time       time.Time `json:"time"`
+	// Provider is the name of the provider, see [WithProviderName].
+	Provider string      `json:"provider"`
+	Added    []KeyChange `json:"added,omitempty"`
+	Removed  []KeyChange `json:"removed,omitempty"`
+	Modified []KeyChange `json:"modified,omitempty"`
+}
+
+// KeyChange is a changed key with the hashes of its old and new values (see [HashValue]).
+// Hashes are empty unless a key is set with [WithAuditHashKey].
+type KeyChange struct {
+	Key string `json:"key"`
+	// OldHash is empty if the key has been added.
+	OldHash string `json:"oldHash,omitempty"`
+	// NewHash is empty if the key has been removed.
+	NewHash string `json:"newHash,omitempty"`
+}
+
+// LogValue implements [slog.LogValuer].
+func (e ChangeEvent) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("generation", e.Generation),
+		slog.Time("time", e.Time),
+		slog.String("provider", e.Provider),
+		slog.Any("added", keyChangesLogValue(e.Added)),
+		slog.Any("removed", keyChangesLogValue(e.Removed)),
+		slog.Any("modified", keyChangesLogValue(e.Modified)),
+	)
+}
+
+func keyChangesLogValue(changes []KeyChange) slog.Value {
+	attrs := make([]slog.Attr, 0, len(changes))
+	for _, c := range changes {
+		var hashes []any
+		if c.OldHash != "" {
+			hashes = append(hashes, slog.String("old", c.OldHash))
+		}
+		if c.NewHash != "" {
+			hashes = append(hashes, slog.String("new", c.NewHash))
+		}
+		attrs = append(attrs, slog.Group(c.Key, hashes...))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// AuditSink receives the change events of [Dynamic]. It is called synchronously during the
+// update cycle, so it must not block for too long.
+type AuditSink interface {
+	Audit(ctx context.Context, event ChangeEvent)
+}
+
+// AuditSinkFunc adapts a function to [AuditSink].
+type AuditSinkFunc func(ctx context.Context, event ChangeEvent)
+
+func (f AuditSinkFunc) Audit(ctx context.Context, event ChangeEvent) {
+	f(ctx, event)
+}
+
+// LogAuditSink logs change events at info level with the logger of the context (see [log.FromContext]).
+// It is the default sink of [Dynamic].
+func LogAuditSink() AuditSink {
+	return AuditSinkFunc(func(ctx context.Context, event ChangeEvent) {
+		log.FromContext(ctx).LogAttrs(ctx, slog.LevelInfo, "config changed", slog.Any("event", event))
+	})
+}
+
+// HashValue returns the hex encoded HMAC-SHA256 of a config value with the key, as used in [KeyChange].
+// Unlike a plain hash, it can't be brute forced to recover low-entropy values, e.g. PINs, without the key.
+func HashValue(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// diffConfig splits the changes into the keys added, removed and modified, keeping their order.
+// Values are hashed with hashKey, if any.
+func diffConfig(changes []RawChange, hashKey []byte) (added, removed, modified []KeyChange) {
+	hash := func(value string) string {
+		if len(hashKey) == 0 {
+			return ""
+		}
+		return HashValue(hashKey, value)
+	}
+
+	for _, c := range changes {
+		switch {
+		case c.Added:
+			added = append(added, KeyChange{Key: c.Key, NewHash: hash(c.New)})
+		case c.Removed:
+			removed = append(removed, KeyChange{Key: c.Key, OldHash: hash(c.Old)})
+		default:
+			modified = append(modified, KeyChange{Key: c.Key, OldHash: hash(c.Old), NewHash: hash(c.New)})
+		}
+	}
+
+	return added, removed, modified
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/raf555/salome/melt/log"
+	"go.uber.org/mock/gomock"
+)
+
+var auditHashKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestDynamicAuditSink(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{
+			"KEPT":    "same",
+			"SECRET":  "old-secret",
+			"REMOVED": "gone",
+		}, nil)
+		gomock.InOrder(
+			providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+				"KEPT":   "same",
+				"SECRET": "new-secret",
+				"ADDED":  "new",
+			}, nil),
+			providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+				"KEPT":   "same",
+				"SECRET": "new-secret",
+				"ADDED":  "new",
+			}, nil).AnyTimes(),
+		)
+
+		var (
+			mu     sync.Mutex
+			events []ChangeEvent
+		)
+		sink := AuditSinkFunc(func(_ context.Context, event ChangeEvent) {
+			mu.Lock()
+			events = append(events, event)
+			mu.Unlock()
+		})
+
+		dynamic, err := NewDynamic(providerMock, WithAuditSink(sink), WithProviderName("vault"), WithAuditHashKey(auditHashKey))
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+
+		dynamic.Start()
+		defer dynamic.Close(t.Context())
+
+		time.Sleep(25 * time.Second)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(events) != 1 {
+			t.Fatalf("expecting 1 event for a single change, got %d", len(events))
+		}
+
+		event := events[0]
+		if event.Generation != 1 || event.Provider != "vault" || event.Time.IsZero() {
+			t.Errorf("unexpected event metadata %+v", event)
+		}
+
+		if expected := []KeyChange{{Key: "ADDED", NewHash: HashValue(auditHashKey, "new")}}; !reflect.DeepEqual(expected, event.Added) {
+			t.Errorf("expecting added %v, got %v", expected, event.Added)
+		}
+		if expected := []KeyChange{{Key: "REMOVED", OldHash: HashValue(auditHashKey, "gone")}}; !reflect.DeepEqual(expected, event.Removed) {
+			t.Errorf("expecting removed %v, got %v", expected, event.Removed)
+		}
+		expected := []KeyChange{{Key: "SECRET", OldHash: HashValue(auditHashKey, "old-secret"), NewHash: HashValue(auditHashKey, "new-secret")}}
+		if !reflect.DeepEqual(expected, event.Modified) {
+			t.Errorf("expecting modified %v, got %v", expected, event.Modified)
+		}
+	})
+}
+
+func TestDiffConfigWithoutHashKey(t *testing.T) {
+	added, removed, modified := diffConfig([]RawChange{
+		{Key: "ADDED", New: "new", Added: true},
+		{Key: "REMOVED", Old: "gone", Removed: true},
+		{Key: "PIN", Old: "1234", New: "4321"},
+	}, nil)
+
+	if expected := []KeyChange{{Key: "ADDED"}}; !reflect.DeepEqual(expected, added) {
+		t.Errorf("expecting added %v, got %v", expected, added)
+	}
+	if expected := []KeyChange{{Key: "REMOVED"}}; !reflect.DeepEqual(expected, removed) {
+		t.Errorf("expecting removed %v, got %v", expected, removed)
+	}
+	if expected := []KeyChange{{Key: "PIN"}}; !reflect.DeepEqual(expected, modified) {
+		t.Errorf("expecting modified %v, got %v", expected, modified)
+	}
+}
+
+func TestHashValue(t *testing.T) {
+	if HashValue(auditHashKey, "1234") == HashValue([]byte("another key"), "1234") {
+		t.Errorf("expecting hashes to depend on the key")
+	}
+	if HashValue(auditHashKey, "1234") != HashValue(auditHashKey, "1234") {
+		t.Errorf("expecting hashes of the same value to match")
+	}
+}
+
+func TestLogAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := log.WithContext(t.Context(), slog.New(slog.NewTextHandler(&buf, nil)))
+
+	LogAuditSink().Audit(ctx, ChangeEvent{
+		Generation: 3,
+		Provider:   "dotenv",
+		Modified:   []KeyChange{{Key: "PASSWORD", OldHash: HashValue(auditHashKey, "old"), NewHash: HashValue(auditHashKey, "new")}},
+	})
+
+	out := buf.String()
+	for _, want := range []string{
+		`msg="config changed"`,
+		"event.generation=3",
+		"event.provider=dotenv",
+		"event.modified.PASSWORD.new=" + HashValue(auditHashKey, "new"),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expecting %q in %q", want, out)
+		}
+	}
+	if strings.Contains(out, "=new ") || strings.Contains(out, "=old ") {
+		t.Errorf("expecting no plaintext values in %q", out)
+	}
+}
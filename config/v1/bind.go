@@ -0,0 +1,44 @@
+package config
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Bind calls set with the value selected from the config now, and again whenever the config changes.
+// It is meant to apply runtime knobs without restarting, see [BindLogLevel] and the BindTraceSampleRatio
+// of melt/otel/otelconfig.
+func Bind[T, V any](getter DynamicConfigGetterWithNotify[T], selector func(T) V, set func(V)) {
+	var (
+		mu       sync.Mutex
+		notified bool
+	)
+
+	// the callback is registered first so a change during Bind isn't missed
+	getter.RegisterCallback(func(cfg T) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		notified = true
+		set(selector(cfg))
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// the callback may have set a newer value already
+	if !notified {
+		set(selector(getter.Get()))
+	}
+}
+
+// BindLogLevel keeps level in sync with the log level of the config. The handlers of the logger
+// must be created with level, e.g. slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}).
+// A [slog.Level] field can be decoded from values like "info" or "DEBUG":
+//
+//	type LogConfig struct {
+//		Level slog.Level `env:"LOG_LEVEL,default=info"`
+//	}
+func BindLogLevel[T any](getter DynamicConfigGetterWithNotify[T], level *slog.LevelVar, selector func(T) slog.Level) {
+	Bind(getter, selector, level.Set)
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestBindRuntimeKnobs(t *testing.T) {
+	type Config struct {
+		LogLevel slog.Level `env:"SALOME_BIND_LOG_LEVEL,default=info"`
+	}
+
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{}, nil)
+		providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+			"SALOME_BIND_LOG_LEVEL": "debug",
+		}, nil).AnyTimes()
+
+		dynamic, err := NewDynamic(providerMock,
+			WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})),
+		)
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+		dynamic.Start()
+		defer dynamic.Close(t.Context())
+
+		getter, err := LoadDynamicConfigToWithNotify[Config](dynamic)
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		var level slog.LevelVar
+		BindLogLevel(getter, &level, func(c Config) slog.Level { return c.LogLevel })
+
+		if level.Level() != slog.LevelInfo {
+			t.Errorf("expecting initial value info, got %v", level.Level())
+		}
+
+		time.Sleep(15 * time.Second)
+
+		if level.Level() != slog.LevelDebug {
+			t.Errorf("expecting updated value debug, got %v", level.Level())
+		}
+	})
+}
+
+func TestBindChangeDuringBind(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	getter := NewMockDynamicConfigGetterWithNotify[string](ctrl)
+
+	// the config changes between the registration of the callback and the initial value
+	getter.EXPECT().RegisterCallback(gomock.Any()).Do(func(cb func(string)) {
+		cb("new")
+	})
+	getter.EXPECT().Get().Return("old").AnyTimes()
+
+	var got string
+	Bind(getter, func(s string) string { return s }, func(s string) { got = s })
+
+	if got != "new" {
+		t.Errorf("expecting the changed value, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+// Command configdoc generates a .env.example, a Markdown table or a JSON schema
+// from a config struct annotated with `env` and `validate` tags.
+//
+// It reads the struct from the Go source of a package, so it can be used with go:generate:
+//
+//	//go:generate go run github.com/raf555/salome/config/v1/cmd/configdoc -type Config -format env -output .env.example
+//
+// Nested structs declared in the same package are followed, including their `prefix=` option.
+// Fields of types declared in other packages are documented as a single key. They must have one, unless
+// they're of a common type of the standard library such as time.Time or url.URL.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	config "github.com/raf555/salome/config/v1"
+	"github.com/raf555/salome/config/v1/internal/srcpkg"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "configdoc:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("configdoc", flag.ContinueOnError)
+	typeName := fs.String("type", "", "name of the config struct (required)")
+	format := fs.String("format", string(config.DocFormatEnv), "output format: env, markdown or jsonschema")
+	dir := fs.String("dir", ".", "directory of the package declaring the struct")
+	output := fs.String("output", "", "output file, defaults to stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *typeName == "" {
+		return fmt.Errorf("-type is required")
+	}
+
+	parsed, err := srcpkg.ParseDir(*dir)
+	if err != nil {
+		return fmt.Errorf("srcpkg.ParseDir: %w", err)
+	}
+	pkg := &sourcePackage{parsed}
+
+	docs, err := pkg.describe(*typeName)
+	if err != nil {
+		return fmt.Errorf("describe: %w", err)
+	}
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("os.Create: %w", err)
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		w = f
+	}
+
+	if err := config.WriteDocs(w, config.DocFormat(*format), docs); err != nil {
+		return fmt.Errorf("config.WriteDocs: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+
+	config "github.com/raf555/salome/config/v1"
+	"github.com/raf555/salome/config/v1/internal/envtag"
+	"github.com/raf555/salome/config/v1/internal/srcpkg"
+)
+
+var basicKinds = map[string]reflect.Kind{
+	"bool":    reflect.Bool,
+	"int":     reflect.Int,
+	"int8":    reflect.Int8,
+	"int16":   reflect.Int16,
+	"int32":   reflect.Int32,
+	"int64":   reflect.Int64,
+	"uint":    reflect.Uint,
+	"uint8":   reflect.Uint8,
+	"uint16":  reflect.Uint16,
+	"uint32":  reflect.Uint32,
+	"uint64":  reflect.Uint64,
+	"byte":    reflect.Uint8,
+	"rune":    reflect.Int32,
+	"float32": reflect.Float32,
+	"float64": reflect.Float64,
+	"string":  reflect.String,
+}
+
+// sourcePackage describes the config structs of a parsed package.
+type sourcePackage struct {
+	*srcpkg.Package
+}
+
+func (p *sourcePackage) describe(typeName string) ([]config.FieldDoc, error) {
+	ts, ok := p.Types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", typeName)
+	}
+
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", typeName)
+	}
+
+	var docs []config.FieldDoc
+	if err := p.walk(st, walkScope{}, map[string]bool{typeName: true}, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// walkScope holds what a nested struct inherits from its parents.
+type walkScope struct {
+	namePrefix string
+	keyPrefix  string
+	required   bool
+	secret     bool
+}
+
+func (p *sourcePackage) walk(st *ast.StructType, scope walkScope, visiting map[string]bool, docs *[]config.FieldDoc) error {
+	for _, field := range st.Fields.List {
+		var tags reflect.StructTag
+		if field.Tag != nil {
+			raw, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return fmt.Errorf("strconv.Unquote: %w", err)
+			}
+			tags = reflect.StructTag(raw)
+		}
+
+		rawTag := tags.Get("env")
+		tag, err := envtag.Parse(rawTag)
+		if err != nil {
+			return fmt.Errorf("%s: %w", types.ExprString(field.Type), err)
+		}
+
+		secret, _ := strconv.ParseBool(tags.Get("secret"))
+
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		if len(names) == 0 { // embedded field
+			names = append(names, srcpkg.ReceiverName(field.Type))
+		}
+
+		for _, name := range names {
+			if !ast.IsExported(name) {
+				continue
+			}
+
+			fieldScope := walkScope{
+				namePrefix: scope.namePrefix + name + ".",
+				keyPrefix:  scope.keyPrefix + tag.Prefix,
+				required:   scope.required || tag.Required,
+				secret:     scope.secret || secret,
+			}
+
+			typeName, nested := p.NestedStruct(field.Type)
+			if nested != nil && !(tag.Key != "" && p.Decoders[typeName]) {
+				if visiting[typeName] {
+					return fmt.Errorf("%s: recursive config struct is not supported", typeName)
+				}
+
+				visiting[typeName] = true
+				err := p.walk(nested, fieldScope, visiting, docs)
+				delete(visiting, typeName)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if tag.Key == "" {
+				// the reflection walker follows any struct without a key, so it can't be skipped
+				if typeExpr, ok := p.Unresolved(field.Type); ok {
+					return fmt.Errorf("%s%s: can't resolve type %s, only structs declared in the package are followed",
+						scope.namePrefix, name, typeExpr)
+				}
+				continue
+			}
+			if ext, ok := p.External(field.Type); ok && ext.Struct && !ext.Decoder {
+				// followed by the reflection walker, with no field to document
+				continue
+			}
+
+			*docs = append(*docs, config.FieldDoc{
+				Key:        scope.keyPrefix + tag.Key,
+				Field:      scope.namePrefix + name,
+				Type:       types.ExprString(field.Type),
+				Kind:       p.kind(field.Type),
+				Default:    tag.Default,
+				Required:   fieldScope.required,
+				Secret:     fieldScope.secret,
+				Validation: tags.Get("validate"),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (p *sourcePackage) kind(expr ast.Expr) reflect.Kind {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return p.kind(e.X)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return reflect.Slice
+		}
+		return reflect.Array
+	case *ast.MapType:
+		return reflect.Map
+	case *ast.Ident:
+		if kind, ok := basicKinds[e.Name]; ok {
+			return kind
+		}
+		if ts, ok := p.Types[e.Name]; ok && !p.Decoders[e.Name] {
+			return p.kind(ts.Type)
+		}
+	case *ast.SelectorExpr:
+		if ext, ok := p.External(e); ok && !ext.Decoder && p.QualifiedName(e) != "time.Duration" {
+			return ext.Kind
+		}
+	}
+	// decoders, durations and unknown types of other packages are written as plain strings
+	return reflect.String
+}
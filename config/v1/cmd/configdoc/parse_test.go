@@ -0,0 +1,152 @@
+package main
+
+import (
+	"log/slog"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/raf555/salome/config/v1"
+	"github.com/raf555/salome/config/v1/internal/srcpkg"
+)
+
+// The config structs below are parsed from this file and compared with the reflection walker.
+
+type Level int
+
+type Mode string
+
+func (m *Mode) UnmarshalText(text []byte) error {
+	*m = Mode(strings.ToLower(string(text)))
+	return nil
+}
+
+type Hosts []string
+
+type FlatConfig struct {
+	Host     string            `env:"HOST,required" validate:"hostname"`
+	Port     int               `env:"PORT,default=8080"`
+	Debug    bool              `env:"DEBUG"`
+	Ratio    float64           `env:"RATIO"`
+	Password string            `env:"PASSWORD" secret:"true"`
+	Timeout  time.Duration     `env:"TIMEOUT,default=5s"`
+	Level    Level             `env:"LEVEL"`
+	Mode     Mode              `env:"MODE"`
+	Hosts    Hosts             `env:"HOSTS"`
+	Ports    []int             `env:"PORTS"`
+	Labels   map[string]string `env:"LABELS"`
+	Endpoint *url.URL          `env:"ENDPOINT"`
+	internal string            `env:"INTERNAL"`
+	Ignored  string
+}
+
+type DatabaseConfig struct {
+	Host     string `env:"HOST,default=localhost"`
+	Password string `env:"PASSWORD"`
+}
+
+type CacheConfig struct {
+	Addr string `env:"ADDR"`
+}
+
+type NestedConfig struct {
+	CacheConfig
+	Database DatabaseConfig  `env:",prefix=DB_,required"`
+	Replica  *DatabaseConfig `env:",prefix=REPLICA_" secret:"true"`
+	Inline   struct {
+		Name string `env:"NAME"`
+	} `env:",prefix=INLINE_"`
+	Started time.Time `env:"STARTED"`
+}
+
+type ExternalPrefixConfig struct {
+	Host   string          `env:"HOST"`
+	Config config.FieldDoc `env:",prefix=X_"`
+}
+
+type ExternalUntaggedConfig struct {
+	Endpoint url.URL
+}
+
+type KnownExternalConfig struct {
+	Timeout  time.Duration
+	Started  time.Time
+	Proxy    url.URL        `env:",prefix=PROXY_"`
+	Location *time.Location `env:"LOCATION"`
+	Month    time.Month     `env:"MONTH"`
+	Level    slog.Level     `env:"LEVEL"`
+	Interval time.Duration  `env:"INTERVAL"`
+}
+
+type EmbeddedExternalConfig struct {
+	config.FieldDoc
+	Host string `env:"HOST"`
+}
+
+type Generic[T any] struct {
+	Value T `env:"VALUE"`
+}
+
+type GenericConfig struct {
+	Nested Generic[string] `env:",prefix=NESTED_"`
+}
+
+type RecursiveConfig struct {
+	Next *RecursiveConfig `env:",prefix=NEXT_"`
+}
+
+func TestDescribe(t *testing.T) {
+	parsed, err := srcpkg.ParseFiles([]string{"parse_test.go"})
+	if err != nil {
+		t.Fatalf("expecting nil error when parsing, got %v", err)
+	}
+	pkg := &sourcePackage{parsed}
+
+	tests := []struct {
+		typeName string
+		typ      reflect.Type
+		err      string
+	}{
+		{typeName: "FlatConfig", typ: reflect.TypeFor[FlatConfig]()},
+		{typeName: "NestedConfig", typ: reflect.TypeFor[NestedConfig]()},
+		{typeName: "ExternalPrefixConfig", err: "Config: can't resolve type config.FieldDoc"},
+		{typeName: "ExternalUntaggedConfig", typ: reflect.TypeFor[ExternalUntaggedConfig]()},
+		{typeName: "KnownExternalConfig", typ: reflect.TypeFor[KnownExternalConfig]()},
+		{typeName: "EmbeddedExternalConfig", err: "FieldDoc: can't resolve type config.FieldDoc"},
+		{typeName: "GenericConfig", err: "Nested: can't resolve type Generic[string]"},
+		{typeName: "RecursiveConfig", err: "recursive config struct is not supported"},
+		{typeName: "Level", err: "type Level is not a struct"},
+		{typeName: "Missing", err: "type Missing not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			docs, err := pkg.describe(tt.typeName)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expecting error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expecting nil error, got %v", err)
+			}
+
+			expected, err := config.DescribeConfigType(tt.typ)
+			if err != nil {
+				t.Fatalf("expecting nil error from the reflection walker, got %v", err)
+			}
+			// types of this package are qualified by reflection only
+			for i := range expected {
+				expected[i].Type = strings.ReplaceAll(expected[i].Type, "main.", "")
+			}
+
+			if !slices.Equal(expected, docs) {
+				t.Errorf("expecting the docs of the reflection walker\n%+v\ngot\n%+v", expected, docs)
+			}
+		})
+	}
+}
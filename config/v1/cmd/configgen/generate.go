@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/raf555/salome/config/v1/internal/envtag"
+	"github.com/raf555/salome/config/v1/internal/fingerprint"
+	"github.com/raf555/salome/config/v1/internal/srcpkg"
+)
+
+// basicParsers are the parse expressions of the basic types, %s being the value and %s the destination.
+var basicParsers = map[string]string{
+	"string":  "config.ParseString(%s, %s)",
+	"bool":    "config.ParseBool(%s, %s)",
+	"int":     "config.ParseInt(%s, 0, %s)",
+	"int8":    "config.ParseInt(%s, 8, %s)",
+	"int16":   "config.ParseInt(%s, 16, %s)",
+	"int32":   "config.ParseInt(%s, 32, %s)",
+	"rune":    "config.ParseInt(%s, 32, %s)",
+	"int64":   "config.ParseInt(%s, 64, %s)",
+	"uint":    "config.ParseUint(%s, 0, %s)",
+	"uint8":   "config.ParseUint(%s, 8, %s)",
+	"byte":    "config.ParseUint(%s, 8, %s)",
+	"uint16":  "config.ParseUint(%s, 16, %s)",
+	"uint32":  "config.ParseUint(%s, 32, %s)",
+	"uint64":  "config.ParseUint(%s, 64, %s)",
+	"uintptr": "config.ParseUint(%s, 0, %s)",
+	"float32": "config.ParseFloat(%s, 32, %s)",
+	"float64": "config.ParseFloat(%s, 64, %s)",
+}
+
+// basicZeros are the zero values of the basic types, to tell whether a field is already set.
+var basicZeros = map[string]string{
+	"string": `""`,
+	"bool":   "false",
+}
+
+// sourcePackage generates the code of the config structs of a parsed package.
+type sourcePackage struct {
+	*srcpkg.Package
+}
+
+// generated is the generated code of a config struct.
+type generated struct {
+	decode bytes.Buffer
+	equal  []string
+	fields []fingerprint.Field
+	// imports of the generated code, besides config
+	imports map[string]bool
+}
+
+func (p *sourcePackage) generate(typeNames []string) ([]byte, error) {
+	var out bytes.Buffer
+	imports := map[string]bool{}
+
+	for _, typeName := range typeNames {
+		typeName = strings.TrimSpace(typeName)
+
+		ts, ok := p.Types[typeName]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found", typeName)
+		}
+		if ts.TypeParams != nil {
+			return nil, fmt.Errorf("type %s: generic config struct is not supported", typeName)
+		}
+
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not a struct", typeName)
+		}
+
+		g := &generated{imports: imports}
+		if err := p.walk(st, g, walkScope{path: "c."}, map[string]bool{typeName: true}); err != nil {
+			return nil, fmt.Errorf("type %s: %w", typeName, err)
+		}
+
+		equal := "true"
+		if len(g.equal) > 0 {
+			equal = strings.Join(g.equal, " &&\n")
+		}
+
+		fmt.Fprintf(&out, `
+// DecodeConfig implements [config.GeneratedConfig].
+func (c *%[1]s) DecodeConfig(d *config.FieldDecoder) {
+%[2]s}
+
+// EqualConfig implements [config.GeneratedConfig].
+func (c *%[1]s) EqualConfig(other any) bool {
+	o, ok := other.(*%[1]s)
+	if !ok {
+		return false
+	}
+
+	return %[3]s
+}
+
+// ConfigFingerprint implements [config.GeneratedConfig].
+func (c *%[1]s) ConfigFingerprint() string {
+	return %[4]q
+}
+`, typeName, g.decode.String(), equal, fingerprint.Sum(g.fields))
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by configgen -type %s. DO NOT EDIT.\n\npackage %s\n\nimport (\n", strings.Join(typeNames, ","), p.Name)
+	for _, path := range []string{"reflect", "slices"} {
+		if imports[path] {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+	}
+	src.WriteString("\n\tconfig \"github.com/raf555/salome/config/v1\"\n)\n")
+	src.Write(out.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format.Source: %w", err)
+	}
+	return formatted, nil
+}
+
+// walkScope holds what a nested struct inherits from its parents, as envconfig does.
+type walkScope struct {
+	namePrefix string
+	keyPrefix  string
+	// path is the Go expression of the struct, e.g. "c.Database."
+	path string
+	// tags are the tags of the parent structs, see fingerprint.Field
+	tags      []string
+	delimiter string
+	required  bool
+	secret    bool
+	noInit    bool
+	overwrite bool
+}
+
+func (p *sourcePackage) walk(st *ast.StructType, g *generated, scope walkScope, visiting map[string]bool) error {
+	for _, field := range st.Fields.List {
+		var tags reflect.StructTag
+		if field.Tag != nil {
+			raw, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return fmt.Errorf("strconv.Unquote: %w", err)
+			}
+			tags = reflect.StructTag(raw)
+		}
+
+		rawTag := tags.Get("env")
+		tag, err := envtag.Parse(rawTag)
+		if err != nil {
+			return fmt.Errorf("%s: %w", types.ExprString(field.Type), err)
+		}
+
+		secret, _ := strconv.ParseBool(tags.Get("secret"))
+
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		if len(names) == 0 { // embedded field
+			names = append(names, srcpkg.ReceiverName(field.Type))
+		}
+
+		for _, name := range names {
+			if !ast.IsExported(name) {
+				continue
+			}
+
+			fieldScope := walkScope{
+				namePrefix: scope.namePrefix + name + ".",
+				keyPrefix:  scope.keyPrefix + tag.Prefix,
+				path:       scope.path + name + ".",
+				tags:       append(slices.Clone(scope.tags), fingerprint.Tag(rawTag, tags.Get("secret"))),
+				delimiter:  scope.delimiter,
+				required:   scope.required || tag.Required,
+				secret:     scope.secret || secret,
+				noInit:     scope.noInit || tag.NoInit,
+				overwrite:  scope.overwrite || tag.Overwrite,
+			}
+			if tag.Delimiter != "" {
+				fieldScope.delimiter = tag.Delimiter
+			}
+
+			if star, ok := field.Type.(*ast.StarExpr); ok {
+				if _, nested := p.NestedStruct(star.X); nested == nil && rawTag == "" {
+					continue
+				}
+				return fmt.Errorf("%s%s: pointer field is not supported", scope.namePrefix, name)
+			}
+
+			typeName, nested := p.NestedStruct(field.Type)
+			if nested != nil && !(tag.Key != "" && p.Decoders[typeName]) {
+				if visiting[typeName] {
+					return fmt.Errorf("%s: recursive config struct is not supported", typeName)
+				}
+
+				visiting[typeName] = true
+				err := p.walk(nested, g, fieldScope, visiting)
+				delete(visiting, typeName)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if tag.Key == "" {
+				// envconfig follows any struct without a key, so it can't be skipped
+				if typeExpr, ok := p.Unresolved(field.Type); ok {
+					return fmt.Errorf("%s%s: can't resolve type %s, only structs declared in the package are followed",
+						scope.namePrefix, name, typeExpr)
+				}
+			}
+			if rawTag == "" {
+				continue
+			}
+			if tag.Key == "" {
+				return fmt.Errorf("%s%s: missing key", scope.namePrefix, name)
+			}
+			if tag.Prefix != "" {
+				return fmt.Errorf("%s%s: prefix on a non-struct field", scope.namePrefix, name)
+			}
+			if fieldScope.required && tag.Default != "" {
+				return fmt.Errorf("%s%s: field cannot be required and have a default value", scope.namePrefix, name)
+			}
+
+			if err := p.leaf(g, scope, fieldScope, name, tag, field.Type); err != nil {
+				return fmt.Errorf("%s%s: %w", scope.namePrefix, name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// leaf generates the decoding and comparison of a field decoded as a whole.
+func (p *sourcePackage) leaf(g *generated, scope, fieldScope walkScope, name string, tag envtag.Tag, expr ast.Expr) error {
+	path := scope.path + name
+	other := "o." + strings.TrimPrefix(path, "c.")
+
+	var (
+		decode string
+		set    string
+		equal  string
+		lazy   bool
+	)
+
+	if elem, ok := sliceElem(expr); ok {
+		if isByte(elem) {
+			decode = fmt.Sprintf("config.ParseBytes(v, &%s)", path)
+		} else {
+			parse, _, _, err := p.parser(elem)
+			if err != nil {
+				return err
+			}
+			item := fmt.Sprintf(parse, "v", "dst")
+			decode = fmt.Sprintf("config.ParseSlice(v, d.Delimiter(spec), &%s, func(v string, dst *%s) error {\n\treturn %s\n})",
+				path, types.ExprString(elem), item)
+		}
+
+		set = path + " != nil"
+		if _, _, comparable, _ := p.parser(elem); comparable || isByte(elem) {
+			equal = fmt.Sprintf("slices.Equal(%s, %s)", path, other)
+			g.imports["slices"] = true
+		} else {
+			equal = fmt.Sprintf("reflect.DeepEqual(%s, %s)", path, other)
+			g.imports["reflect"] = true
+		}
+	} else {
+		parse, zero, comparable, err := p.parser(expr)
+		if err != nil {
+			return err
+		}
+
+		decode = fmt.Sprintf(parse, "v", "&"+path)
+		if comparable {
+			set = fmt.Sprintf("%s != %s", path, zero)
+			if zero == "false" {
+				set = path
+			}
+			equal = fmt.Sprintf("%s == %s", path, other)
+		} else {
+			// decoders are compared as a whole, as reflect.DeepEqual would
+			set = fmt.Sprintf("!config.IsZero(%s)", path)
+			equal = fmt.Sprintf("reflect.DeepEqual(%s, %s)", path, other)
+			g.imports["reflect"] = true
+			lazy = p.isStruct(expr)
+		}
+	}
+
+	spec := []string{
+		"Name: " + strconv.Quote(scope.namePrefix+name),
+		"Key: " + strconv.Quote(scope.keyPrefix+tag.Key),
+		"TagKey: " + strconv.Quote(tag.Key),
+	}
+	if tag.Default != "" {
+		spec = append(spec, "Default: "+strconv.Quote(tag.Default))
+	}
+	if fieldScope.delimiter != "" {
+		spec = append(spec, "Delimiter: "+strconv.Quote(fieldScope.delimiter))
+	}
+	for _, opt := range []struct {
+		name string
+		ok   bool
+	}{
+		{"Required", fieldScope.required},
+		{"Secret", fieldScope.secret},
+		{"Overwrite", fieldScope.overwrite},
+		{"NoInit", fieldScope.noInit},
+		{"Lazy", lazy},
+	} {
+		if opt.ok {
+			spec = append(spec, opt.name+": true")
+		}
+	}
+
+	fmt.Fprintf(&g.decode, "\t{\n\t\tspec := config.FieldSpec{%s}\n", strings.Join(spec, ", "))
+	fmt.Fprintf(&g.decode, "\t\td.Decode(spec, %s, func(v string) error {\n\t\t\treturn %s\n\t\t})\n\t}\n", set, decode)
+	g.equal = append(g.equal, equal)
+	g.fields = append(g.fields, fingerprint.Field{
+		Name:    scope.namePrefix + name,
+		Key:     scope.keyPrefix + tag.Key,
+		Type:    p.TypeString(expr),
+		Decoder: p.IsDecoder(expr),
+		Tags:    fieldScope.tags,
+	})
+
+	return nil
+}
+
+// parser returns the parse expression of a type (see basicParsers), its zero value and whether
+// it's compared with ==.
+func (p *sourcePackage) parser(expr ast.Expr) (parse, zero string, comparable bool, err error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if parse, ok := basicParsers[e.Name]; ok {
+			zero, ok := basicZeros[e.Name]
+			if !ok {
+				zero = "0"
+			}
+			return parse, zero, true, nil
+		}
+
+		ts, ok := p.Types[e.Name]
+		if !ok {
+			return "", "", false, fmt.Errorf("unsupported type %s", e.Name)
+		}
+		if p.Decoders[e.Name] {
+			return "config.DecodeValue(d.Context(), %s, %s)", "", false, nil
+		}
+		if ts.Assign.IsValid() { // alias
+			return p.parser(ts.Type)
+		}
+		if base, ok := ts.Type.(*ast.Ident); ok {
+			return p.parser(base)
+		}
+	case *ast.SelectorExpr:
+		if p.QualifiedName(e) == "time.Duration" {
+			return "config.ParseDuration(%s, %s)", "0", true, nil
+		}
+		// types of other packages are assumed to be decoders
+		return "config.DecodeValue(d.Context(), %s, %s)", "", false, nil
+	}
+
+	return "", "", false, fmt.Errorf("unsupported type %s", types.ExprString(expr))
+}
+
+// isStruct reports whether expr is a struct type, types of other packages being assumed to be.
+func (p *sourcePackage) isStruct(expr ast.Expr) bool {
+	if e, ok := expr.(*ast.Ident); ok {
+		ts, ok := p.Types[e.Name]
+		if !ok {
+			return false
+		}
+		_, ok = ts.Type.(*ast.StructType)
+		return ok
+	}
+	_, ok := expr.(*ast.SelectorExpr)
+	return ok
+}
+
+func sliceElem(expr ast.Expr) (ast.Expr, bool) {
+	at, ok := expr.(*ast.ArrayType)
+	if !ok || at.Len != nil {
+		return nil, false
+	}
+	return at.Elt, true
+}
+
+func isByte(expr ast.Expr) bool {
+	e, ok := expr.(*ast.Ident)
+	return ok && (e.Name == "byte" || e.Name == "uint8")
+}
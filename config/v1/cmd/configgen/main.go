@@ -0,0 +1,70 @@
+// Command configgen generates the methods of [config.GeneratedConfig] for config structs annotated
+// with `env` tags, so they are decoded and compared without reflection.
+//
+// It reads the structs from the Go source of a package, so it can be used with go:generate:
+//
+//	//go:generate go run github.com/raf555/salome/config/v1/cmd/configgen -type Config
+//
+// Nested structs declared in the same package are followed, including their `prefix=` option.
+// Fields may be of a basic type (or a type declared in this package based on one), time.Duration,
+// a slice of those, or a type implementing one of the decoder interfaces of envconfig. Types declared
+// in other packages are assumed to be such decoders. Pointers and maps are not supported.
+//
+// The generated code has a fingerprint of the fields, their types and `env` tags of the struct. If the struct changes
+// without generating the code again, the loader detects it and falls back to reflection.
+//
+// [config.GeneratedConfig]: https://pkg.go.dev/github.com/raf555/salome/config/v1#GeneratedConfig
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/raf555/salome/config/v1/internal/srcpkg"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "configgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("configgen", flag.ContinueOnError)
+	typeNames := fs.String("type", "", "comma-separated names of the config structs (required)")
+	dir := fs.String("dir", ".", "directory of the package declaring the structs")
+	output := fs.String("output", "config.gen.go", "output file, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *typeNames == "" {
+		return fmt.Errorf("-type is required")
+	}
+
+	parsed, err := srcpkg.ParseDir(*dir)
+	if err != nil {
+		return fmt.Errorf("srcpkg.ParseDir: %w", err)
+	}
+	pkg := &sourcePackage{parsed}
+
+	src, err := pkg.generate(strings.Split(*typeNames, ","))
+	if err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+
+	if *output == "-" {
+		_, err := stdout.Write(src)
+		return err
+	}
+
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DynamicStatus is a snapshot of the state of a [Dynamic], with secrets redacted.
+type DynamicStatus struct {
+	// Generation is incremented on every applied change, see [ChangeEvent].
+	Generation uint64 `json:"generation"`
+	// LastFetch is the time of the last successful fetch.
+	LastFetch time.Time `json:"lastFetch"`
+	// ConsecutiveFailures is the number of failed fetches since LastFetch, see [Dynamic.Health].
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// LastError is the last error of the background process, if any, at LastErrorTime. It only has the failed
+	// operation, the kind of error and the keys of the invalid fields, as the error may contain config values,
+	// e.g. "load failed: invalid field(s): DB_PORT (decode)". The full error is passed to the ErrCallback.
+	LastError     string    `json:"lastError,omitempty"`
+	LastErrorTime time.Time `json:"lastErrorTime,omitzero"`
+	// History is the latest change events, oldest first (see [WithChangeHistory]).
+	History []ChangeEvent      `json:"history"`
+	Configs []RegisteredConfig `json:"configs"`
+}
+
+// RegisteredConfig is a registered config with its current values.
+type RegisteredConfig struct {
+	// Type is the Go type of the config, e.g. "*app.DatabaseConfig".
+	Type   string          `json:"type"`
+	Fields []RedactedField `json:"fields"`
+	// Error is set if the config can't be redacted.
+	Error string `json:"error,omitempty"`
+}
+
+// Status returns a snapshot of the state of d. Configs are sorted by type, then in registration order.
+func (d *Dynamic) Status() DynamicStatus {
+	d.mu.RLock()
+	status := DynamicStatus{
+		Generation:          d.generation,
+		LastFetch:           d.lastFetch,
+		ConsecutiveFailures: d.fetchFailures,
+		History:             slices.Clone(d.history),
+	}
+	if d.lastErr != nil {
+		status.LastError = statusError(d.lastErrOp, d.lastErr)
+		status.LastErrorTime = d.lastErrAt
+	}
+	d.mu.RUnlock()
+
+	type ordered struct {
+		cfg   RegisteredConfig
+		order uint64
+	}
+
+	var configs []ordered
+	d.configRegistry.Range(func(_ any, value registrant[any]) bool {
+		cfg := RegisteredConfig{Type: fmt.Sprintf("%T", value.currentCfg)}
+
+		fields, err := Redacted(value.currentCfg)
+		if err != nil {
+			cfg.Error = err.Error()
+		}
+		cfg.Fields = fields
+
+		configs = append(configs, ordered{cfg: cfg, order: value.order})
+		return true
+	})
+
+	slices.SortFunc(configs, func(a, b ordered) int {
+		return cmp.Or(cmp.Compare(a.cfg.Type, b.cfg.Type), cmp.Compare(a.order, b.order))
+	})
+
+	for _, c := range configs {
+		status.Configs = append(status.Configs, c.cfg)
+	}
+
+	return status
+}
+
+// statusError describes err of the operation without its message, which may contain config values.
+func statusError(op string, err error) string {
+	var verr *ValidationError
+	switch {
+	case errors.As(err, &verr):
+		keys := make([]string, len(verr.Fields))
+		for i, f := range verr.Fields {
+			keys[i] = fmt.Sprintf("%s (%s)", cmp.Or(f.Key, f.Field), f.Rule)
+		}
+		return fmt.Sprintf("%s failed: invalid field(s): %s", op, strings.Join(keys, ", "))
+	case errors.Is(err, context.DeadlineExceeded):
+		return op + " failed: " + context.DeadlineExceeded.Error()
+	case errors.Is(err, context.Canceled):
+		return op + " failed: " + context.Canceled.Error()
+	default:
+		return op + " failed"
+	}
+}
+
+type debugHandlerOptions struct {
+	authorizeRefresh func(r *http.Request) bool
+}
+
+// DebugHandlerOption configures the handler of [NewDebugHandler].
+type DebugHandlerOption func(*debugHandlerOptions)
+
+// WithDebugRefresh enables POST requests to force a refresh, bypassing the settle period (see [Dynamic.ForceRefresh]).
+// Requests are rejected with 403 Forbidden unless authorize returns true, e.g. after checking a token.
+func WithDebugRefresh(authorize func(r *http.Request) bool) DebugHandlerOption {
+	return func(o *debugHandlerOptions) {
+		o.authorizeRefresh = authorize
+	}
+}
+
+// NewDebugHandler returns a handler exposing the status of d (see [Dynamic.Status]), meant for an
+// internal admin mux:
+//
+//	mux.Handle("/debug/config", config.NewDebugHandler(dynamic))
+//
+// GET serves an HTML page, or JSON if the request accepts "application/json" or has "?format=json".
+// POST forces a refresh if enabled with [WithDebugRefresh], then serves the status as JSON.
+func NewDebugHandler(d *Dynamic, opts ...DebugHandlerOption) http.Handler {
+	var o debugHandlerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost:
+			if o.authorizeRefresh == nil {
+				w.Header().Set("Allow", "GET, HEAD")
+				http.Error(w, "refresh is not enabled", http.StatusMethodNotAllowed)
+				return
+			}
+			if !o.authorizeRefresh(r) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			// the error is part of the status
+			_, _ = d.ForceRefresh(r.Context())
+			writeStatusJSON(w, d.Status())
+			return
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		status := d.Status()
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			writeStatusJSON(w, status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := debugPage.Execute(w, status); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func writeStatusJSON(w http.ResponseWriter, status DynamicStatus) {
+	w.Header().Set("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(status)
+}
+
+var debugPage = template.Must(template.New("config").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Config</title>
+<style>body{font-family:sans-serif}table{border-collapse:collapse;margin-bottom:1em}td,th{border:1px solid #ccc;padding:2px 6px;text-align:left}</style>
+</head>
+<body>
+<h1>Config</h1>
+<p>Generation {{.Generation}}, last fetch {{.LastFetch.Format "2006-01-02T15:04:05Z07:00"}}</p>
+{{if .LastError}}<p>Last error at {{.LastErrorTime.Format "2006-01-02T15:04:05Z07:00"}}: <code>{{.LastError}}</code></p>{{end}}
+{{range .Configs}}
+<h2><code>{{.Type}}</code></h2>
+{{if .Error}}<p><code>{{.Error}}</code></p>{{end}}
+<table><tr><th>Key</th><th>Field</th><th>Value</th></tr>
+{{range .Fields}}<tr><td><code>{{.Key}}</code></td><td>{{.Field}}</td><td><code>{{.Value}}</code></td></tr>
+{{end}}</table>
+{{end}}
+<h2>History</h2>
+<table><tr><th>Generation</th><th>Time</th><th>Added</th><th>Removed</th><th>Modified</th></tr>
+{{range .History}}<tr><td>{{.Generation}}</td><td>{{.Time.Format "2006-01-02T15:04:05Z07:00"}}</td><td>{{range .Added}}<code>{{.Key}}</code> {{end}}</td><td>{{range .Removed}}<code>{{.Key}}</code> {{end}}</td><td>{{range .Modified}}<code>{{.Key}}</code> {{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
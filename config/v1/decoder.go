@@ -0,0 +1,168 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+// decodeFunc decodes a config value into a value assignable to the registered type.
+type decodeFunc func(ctx context.Context, value string) (reflect.Value, error)
+
+// WithDecoder registers a decoder for fields of type T (or *T).
+// It takes precedence over envconfig's decoding, including decoders implemented by T itself,
+// so it can be used for types the package doesn't own, e.g. [DecodeURL] or [DecodeLocation].
+//
+// Fields decoded this way still honor the required and default options of the `env` tag, and mutators.
+func WithDecoder[T any](fn func(ctx context.Context, value string) (T, error)) LoaderOption {
+	return func(o *loaderOptions) {
+		if o.decoders == nil {
+			o.decoders = make(map[reflect.Type]decodeFunc)
+		}
+
+		o.decoders[reflect.TypeFor[T]()] = func(ctx context.Context, value string) (reflect.Value, error) {
+			v, err := fn(ctx, value)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&v).Elem(), nil
+		}
+	}
+}
+
+// WithMutators registers envconfig mutators, applied in order to every value before it's decoded.
+// See [TrimSpaceMutator] and [ToLowerMutator].
+func WithMutators(mutators ...envconfig.Mutator) LoaderOption {
+	return func(o *loaderOptions) {
+		o.mutators = append(o.mutators, mutators...)
+	}
+}
+
+// WithDelimiter sets the default delimiter of slice and map items. Defaults to ",".
+// The `delimiter=` option of the `env` tag takes precedence.
+func WithDelimiter(delimiter string) LoaderOption {
+	return func(o *loaderOptions) {
+		o.delimiter = delimiter
+	}
+}
+
+// WithSeparator sets the default separator of map keys and values. Defaults to ":".
+// The `separator=` option of the `env` tag takes precedence.
+func WithSeparator(separator string) LoaderOption {
+	return func(o *loaderOptions) {
+		o.separator = separator
+	}
+}
+
+// TrimSpaceMutator trims leading and trailing white space of every value.
+func TrimSpaceMutator() envconfig.Mutator {
+	return envconfig.MutatorFunc(func(_ context.Context, _, _, _, currentValue string) (string, bool, error) {
+		return strings.TrimSpace(currentValue), false, nil
+	})
+}
+
+// ToLowerMutator lowercases the values of the given keys, or of every key if none is given.
+// Keys are matched against the fully resolved key, including prefixes.
+func ToLowerMutator(keys ...string) envconfig.Mutator {
+	return envconfig.MutatorFunc(func(_ context.Context, _, resolvedKey, _, currentValue string) (string, bool, error) {
+		if len(keys) > 0 && !slices.Contains(keys, resolvedKey) {
+			return currentValue, false, nil
+		}
+		return strings.ToLower(currentValue), false, nil
+	})
+}
+
+// DecodeURL parses the value as a URL. It can be used with [WithDecoder].
+func DecodeURL(_ context.Context, value string) (*url.URL, error) {
+	return url.Parse(value)
+}
+
+// DecodeLocation loads the time zone with the given name, e.g. "Asia/Jakarta".
+// It can be used with [WithDecoder].
+func DecodeLocation(_ context.Context, value string) (*time.Location, error) {
+	return time.LoadLocation(value)
+}
+
+// ByteSize is a size in bytes decoded from values like "512", "10MiB" or "1.5GB".
+type ByteSize int64
+
+// EnvDecode implements [envconfig.Decoder]. Empty values leave b unchanged.
+func (b *ByteSize) EnvDecode(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	n, err := ParseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(n)
+	return nil
+}
+
+// ErrInvalidByteSize is returned by [ParseByteSize] on malformed input.
+var ErrInvalidByteSize = errors.New("config: invalid byte size")
+
+var byteSizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"m":   1e6,
+	"mb":  1e6,
+	"g":   1e9,
+	"gb":  1e9,
+	"t":   1e12,
+	"tb":  1e12,
+	"p":   1e15,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// ParseByteSize parses a size with an optional decimal (kB, MB, ...) or binary (KiB, MiB, ...) unit.
+// Units are case-insensitive. It can be used to build decoders for other size types with [WithDecoder].
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown unit %q", ErrInvalidByteSize, unit)
+	}
+
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidByteSize, err)
+	}
+
+	size := n * multiplier
+	if size < 0 {
+		return 0, fmt.Errorf("%w: %q is negative", ErrInvalidByteSize, s)
+	}
+	// float64(math.MaxInt64) rounds up to 1<<63, which doesn't fit
+	if size >= 1<<63 {
+		return 0, fmt.Errorf("%w: %q overflows", ErrInvalidByteSize, s)
+	}
+
+	return int64(size), nil
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldDoc documents a single config key of a config struct.
+type FieldDoc struct {
+	// Key is the fully resolved key, including prefixes of parent structs.
+	Key string `json:"key"`
+	// Field is the dotted Go path of the field, e.g. "Database.Host".
+	Field string `json:"field"`
+	// Type is the Go type of the field, e.g. "time.Duration".
+	Type string `json:"type"`
+	// Kind is the kind of value the key holds. Types decoded from text (e.g. via [encoding.TextUnmarshaler])
+	// are reported as [reflect.String].
+	Kind       reflect.Kind `json:"-"`
+	Default    string       `json:"default,omitempty"`
+	Required   bool         `json:"required"`
+	Secret     bool         `json:"secret"`
+	Validation string       `json:"validation,omitempty"`
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// DocFormat is an output format of [WriteDocs].
+type DocFormat string
+
+const (
+	// DocFormatEnv renders a .env.example file.
+	DocFormatEnv DocFormat = "env"
+	// DocFormatMarkdown renders a Markdown table.
+	DocFormatMarkdown DocFormat = "markdown"
+	// DocFormatJSONSchema renders a JSON schema of the flat key-value config.
+	DocFormatJSONSchema DocFormat = "jsonschema"
+)
+
+// DescribeConfig documents every key read when loading config into T.
+func DescribeConfig[T any]() ([]FieldDoc, error) {
+	return DescribeConfigType(reflect.TypeFor[T]())
+}
+
+// DescribeConfigType is like [DescribeConfig] but takes the struct type (or pointer to it) as a value.
+func DescribeConfigType(t reflect.Type) ([]FieldDoc, error) {
+	fields, err := configFields(t, nil)
+	if err != nil {
+		return nil, fmt.Errorf("configFields: %w", err)
+	}
+
+	docs := make([]FieldDoc, 0, len(fields))
+	for _, f := range fields {
+		ft := f.Field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		kind := ft.Kind()
+		if isDecoder(ft) || ft == durationType {
+			kind = reflect.String
+		}
+
+		docs = append(docs, FieldDoc{
+			Key:        f.Key,
+			Field:      f.Name,
+			Type:       f.Field.Type.String(),
+			Kind:       kind,
+			Default:    f.Tag.Default,
+			Required:   f.Required,
+			Secret:     f.Secret,
+			Validation: f.Field.Tag.Get("validate"),
+		})
+	}
+
+	return docs, nil
+}
+
+// WriteDocs renders docs to w in the given format.
+func WriteDocs(w io.Writer, format DocFormat, docs []FieldDoc) error {
+	switch format {
+	case DocFormatEnv:
+		return writeEnvExample(w, docs)
+	case DocFormatMarkdown:
+		return writeMarkdown(w, docs)
+	case DocFormatJSONSchema:
+		return writeJSONSchema(w, docs)
+	}
+	return fmt.Errorf("unknown doc format %q", format)
+}
+
+func writeEnvExample(w io.Writer, docs []FieldDoc) error {
+	var sb strings.Builder
+	for i, doc := range docs {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+
+		attrs := []string{doc.Type}
+		if doc.Required {
+			attrs = append(attrs, "required")
+		}
+		if doc.Secret {
+			attrs = append(attrs, "secret")
+		}
+		if doc.Validation != "" {
+			attrs = append(attrs, "validate: "+doc.Validation)
+		}
+		fmt.Fprintf(&sb, "# %s (%s)\n", doc.Field, strings.Join(attrs, ", "))
+
+		val := doc.Default
+		if strings.ContainsAny(val, " \t#'\"\\\n") {
+			val = strconv.Quote(val)
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", doc.Key, val)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeMarkdown(w io.Writer, docs []FieldDoc) error {
+	var sb strings.Builder
+	sb.WriteString("| Key | Field | Type | Default | Required | Secret | Validation |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+
+	for _, doc := range docs {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s | %s |\n",
+			markdownCode(doc.Key),
+			markdownEscape(doc.Field),
+			markdownCode(doc.Type),
+			markdownCode(doc.Default),
+			markdownBool(doc.Required),
+			markdownBool(doc.Secret),
+			markdownCode(doc.Validation),
+		)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + markdownEscape(s) + "`"
+}
+
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func writeJSONSchema(w io.Writer, docs []FieldDoc) error {
+	properties := make(map[string]any, len(docs))
+	required := []string{}
+
+	for _, doc := range docs {
+		schemaType := jsonSchemaType(doc.Kind)
+		prop := map[string]any{
+			"type":       schemaType,
+			"x-go-field": doc.Field,
+			"x-go-type":  doc.Type,
+		}
+		if doc.Default != "" {
+			prop["default"] = jsonSchemaDefault(schemaType, doc.Default)
+		}
+		if doc.Validation != "" {
+			prop["x-validate"] = doc.Validation
+		}
+		if doc.Secret {
+			prop["writeOnly"] = true
+		}
+		properties[doc.Key] = prop
+
+		if doc.Required {
+			required = append(required, doc.Key)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	})
+}
+
+func jsonSchemaType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	// slices and maps are delimited strings in the flat config
+	return "string"
+}
+
+func jsonSchemaDefault(schemaType, def string) any {
+	switch schemaType {
+	case "boolean":
+		if b, err := strconv.ParseBool(def); err == nil {
+			return b
+		}
+	case "integer":
+		if i, err := strconv.ParseInt(def, 0, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(def, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
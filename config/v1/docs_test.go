@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raf555/salome/encoding/hex"
+)
+
+func TestDescribeConfig(t *testing.T) {
+	type Database struct {
+		Host string `env:"HOST,required" validate:"hostname"`
+		Port int    `env:"PORT,default=5432"`
+	}
+
+	type Config struct {
+		Name     string        `env:"NAME,default=my app"`
+		Timeout  time.Duration `env:"TIMEOUT,default=5s"`
+		Key      hex.String    `env:"KEY" secret:"true"`
+		Database *Database     `env:",prefix=DB_"`
+		Ignored  string
+	}
+
+	docs, err := DescribeConfig[Config]()
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	expected := []FieldDoc{
+		{Key: "NAME", Field: "Name", Type: "string", Kind: reflect.String, Default: "my app"},
+		{Key: "TIMEOUT", Field: "Timeout", Type: "time.Duration", Kind: reflect.String, Default: "5s"},
+		{Key: "KEY", Field: "Key", Type: "hex.String", Kind: reflect.String, Secret: true},
+		{Key: "DB_HOST", Field: "Database.Host", Type: "string", Kind: reflect.String, Required: true, Validation: "hostname"},
+		{Key: "DB_PORT", Field: "Database.Port", Type: "int", Kind: reflect.Int, Default: "5432"},
+	}
+	if !reflect.DeepEqual(expected, docs) {
+		t.Errorf("expecting docs %+v, got %+v", expected, docs)
+	}
+}
+
+func TestWriteDocs(t *testing.T) {
+	docs := []FieldDoc{
+		{Key: "NAME", Field: "Name", Type: "string", Kind: reflect.String, Default: "my app"},
+		{Key: "DB_PORT", Field: "Database.Port", Type: "int", Kind: reflect.Int, Default: "5432", Required: true, Validation: "min=1|max=65535"},
+		{Key: "TOKEN", Field: "Token", Type: "string", Kind: reflect.String, Secret: true},
+	}
+
+	t.Run("env", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteDocs(&buf, DocFormatEnv, docs); err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		expected := "# Name (string)\nNAME=\"my app\"\n\n# Database.Port (int, required, validate: min=1|max=65535)\nDB_PORT=5432\n\n# Token (string, secret)\nTOKEN=\n"
+		if buf.String() != expected {
+			t.Errorf("expecting %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteDocs(&buf, DocFormatMarkdown, docs); err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		if !strings.Contains(buf.String(), "| `DB_PORT` | Database.Port | `int` | `5432` | yes | no | `min=1\\|max=65535` |") {
+			t.Errorf("expecting escaped DB_PORT row, got %s", buf.String())
+		}
+	})
+
+	t.Run("jsonschema", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteDocs(&buf, DocFormatJSONSchema, docs); err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		var schema struct {
+			Properties map[string]struct {
+				Type    string `json:"type"`
+				Default any    `json:"default"`
+			} `json:"properties"`
+			Required []string `json:"required"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &schema); err != nil {
+			t.Fatalf("expecting valid JSON, got %v", err)
+		}
+
+		port := schema.Properties["DB_PORT"]
+		if port.Type != "integer" || port.Default != float64(5432) {
+			t.Errorf("expecting DB_PORT to be integer with default 5432, got %+v", port)
+		}
+		if !reflect.DeepEqual(schema.Required, []string{"DB_PORT"}) {
+			t.Errorf("expecting DB_PORT to be required, got %v", schema.Required)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := WriteDocs(&bytes.Buffer{}, "yaml", docs); err == nil {
+			t.Errorf("expecting error for unknown format")
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// DryRunResult is the outcome of loading a candidate config into a registered config.
+type DryRunResult struct {
+	// Key is the registration key.
+	Key any
+	// Type is the Go type of the config, e.g. "*app.DatabaseConfig".
+	Type string
+	// Err is nil if the candidate is valid for the config. Invalid fields are reported with a
+	// wrapped [ValidationError].
+	Err error
+}
+
+// DryRun loads the candidate config into every registered config, decoding and validating it like
+// an update would, without applying it. Results are sorted by type, then in registration order.
+// See package config/v1/dryrun for a command line front end.
+func (d *Dynamic) DryRun(ctx context.Context, candidate map[string]string) []DryRunResult {
+	type ordered struct {
+		result DryRunResult
+		order  uint64
+	}
+
+	var results []ordered
+	d.configRegistry.Range(func(key any, value registrant[any]) bool {
+		dst := value.factory()
+
+		result := DryRunResult{
+			Key:  key,
+			Type: fmt.Sprintf("%T", dst),
+		}
+		if err := d.loader.loadConfigFromMapTo(ctx, dst, candidate); err != nil {
+			result.Err = fmt.Errorf("d.loader.loadConfigFromMapTo: %w", err)
+		}
+
+		results = append(results, ordered{result: result, order: value.order})
+		return true
+	})
+
+	slices.SortFunc(results, func(a, b ordered) int {
+		return cmp.Or(cmp.Compare(a.result.Type, b.result.Type), cmp.Compare(a.order, b.order))
+	})
+
+	out := make([]DryRunResult, len(results))
+	for i, r := range results {
+		out[i] = r.result
+	}
+	return out
+}
+
+// DryRunOverlay is like [Dynamic.DryRun], with the candidate overlaid on the current config instead of
+// replacing it, e.g. to check a change of a few keys.
+func (d *Dynamic) DryRunOverlay(ctx context.Context, candidate map[string]string) []DryRunResult {
+	d.mu.RLock()
+	merged := make(map[string]string, len(d.currentCfg)+len(candidate))
+	maps.Copy(merged, d.currentCfg)
+	d.mu.RUnlock()
+
+	maps.Copy(merged, candidate)
+	return d.DryRun(ctx, merged)
+}
@@ -0,0 +1,185 @@
+// Package dryrun is a command line front end of [config.Dynamic.DryRun], meant to be mounted as
+// a subcommand of a service, so a candidate config is checked against the configs the service registers:
+//
+//	if os.Args[1] == "config-check" {
+//		err := dryrun.Run(ctx, dynamic, os.Args[2:], os.Stdout)
+//		...
+//	}
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	config "github.com/raf555/salome/config/v1"
+)
+
+// ErrFailed is returned by [Run] when the candidate is invalid for a registered config.
+var ErrFailed = errors.New("dryrun: dry run failed")
+
+// Run parses the flags in args, reads the candidate and writes the results of the dry run to stdout.
+//
+// Flags:
+//
+//	-file    candidate .env or .json file (required)
+//	-merge   overlay the candidate on the current config instead of replacing it
+//	-format  output format: text or json
+//
+// It returns [ErrFailed] if any registered config rejects the candidate.
+func Run(ctx context.Context, d *config.Dynamic, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("dry-run", flag.ContinueOnError)
+	filename := fs.String("file", "", "candidate .env or .json file (required)")
+	merge := fs.Bool("merge", false, "overlay the candidate on the current config instead of replacing it")
+	format := fs.String("format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *filename == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	candidate, err := ReadCandidate(*filename)
+	if err != nil {
+		return fmt.Errorf("ReadCandidate: %w", err)
+	}
+
+	var results []config.DryRunResult
+	if *merge {
+		results = d.DryRunOverlay(ctx, candidate)
+	} else {
+		results = d.DryRun(ctx, candidate)
+	}
+
+	switch *format {
+	case "text":
+		err = writeText(stdout, results)
+	case "json":
+		err = writeJSON(stdout, results)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if r.Err != nil {
+			return ErrFailed
+		}
+	}
+	return nil
+}
+
+// ReadCandidate reads a candidate config from a .env file, or from a JSON object if the file name
+// ends with ".json". JSON values that are not strings are written as their JSON encoding.
+func ReadCandidate(filename string) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if !strings.EqualFold(filepath.Ext(filename), ".json") {
+		cfg, err := godotenv.Parse(f)
+		if err != nil {
+			return nil, fmt.Errorf("godotenv.Parse: %w", err)
+		}
+		return cfg, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(f).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("json.Decode: %w", err)
+	}
+
+	cfg := make(map[string]string, len(raw))
+	for key, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			s = string(value)
+		}
+		cfg[key] = s
+	}
+	return cfg, nil
+}
+
+func writeText(w io.Writer, results []config.DryRunResult) error {
+	var sb strings.Builder
+	for _, r := range results {
+		var verr *config.ValidationError
+		switch {
+		case r.Err == nil:
+			fmt.Fprintf(&sb, "ok   %s\n", r.Type)
+		case errors.As(r.Err, &verr):
+			fmt.Fprintf(&sb, "FAIL %s\n", r.Type)
+			for _, f := range verr.Fields {
+				fmt.Fprintf(&sb, "     %s\n", f)
+			}
+		default:
+			fmt.Fprintf(&sb, "FAIL %s: %v\n", r.Type, r.Err)
+		}
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("io.WriteString: %w", err)
+	}
+	return nil
+}
+
+type jsonResult struct {
+	Type   string         `json:"type"`
+	OK     bool           `json:"ok"`
+	Error  string         `json:"error,omitempty"`
+	Fields []jsonFieldErr `json:"fields,omitempty"`
+}
+
+type jsonFieldErr struct {
+	Field   string `json:"field"`
+	Key     string `json:"key,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func writeJSON(w io.Writer, results []config.DryRunResult) error {
+	out := make([]jsonResult, 0, len(results))
+	for _, r := range results {
+		jr := jsonResult{Type: r.Type, OK: r.Err == nil}
+		if r.Err != nil {
+			jr.Error = r.Err.Error()
+		}
+
+		var verr *config.ValidationError
+		if errors.As(r.Err, &verr) {
+			for _, f := range verr.Fields {
+				jr.Fields = append(jr.Fields, jsonFieldErr{
+					Field:   f.Field,
+					Key:     f.Key,
+					Value:   f.Value,
+					Rule:    f.Rule,
+					Message: f.Message,
+				})
+			}
+		}
+
+		out = append(out, jr)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("enc.Encode: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package dryrun
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	config "github.com/raf555/salome/config/v1"
+	"go.uber.org/mock/gomock"
+)
+
+type dbConfig struct {
+	Port int `env:"SALOME_DRY_RUN_PORT" validate:"min=1,max=65535"`
+}
+
+type appConfig struct {
+	Name string `env:"SALOME_DRY_RUN_NAME,required"`
+}
+
+func newDynamic(t *testing.T) *config.Dynamic {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	providerMock := config.NewMockProvider(ctrl)
+	providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{
+		"SALOME_DRY_RUN_PORT": "5432",
+		"SALOME_DRY_RUN_NAME": "app",
+	}, nil)
+
+	dynamic, err := config.NewDynamic(providerMock,
+		config.WithLoaderOptions(config.WithLookupOrder(config.LookupProviderOnly)),
+	)
+	if err != nil {
+		t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+	}
+
+	if err := dynamic.RegisterConfig(&dbConfig{}, func() any { return &dbConfig{} }); err != nil {
+		t.Fatalf("expecting nil error when registering, got %v", err)
+	}
+	if err := dynamic.RegisterConfig(&appConfig{}, func() any { return &appConfig{} }); err != nil {
+		t.Fatalf("expecting nil error when registering, got %v", err)
+	}
+
+	return dynamic
+}
+
+func TestRun(t *testing.T) {
+	dynamic := newDynamic(t)
+	dir := t.TempDir()
+
+	envFile := filepath.Join(dir, "candidate.env")
+	if err := os.WriteFile(envFile, []byte("SALOME_DRY_RUN_PORT=0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err := Run(t.Context(), dynamic, []string{"-file", envFile, "-merge"}, &out)
+	if !errors.Is(err, ErrFailed) {
+		t.Errorf("expecting ErrFailed, got %v", err)
+	}
+
+	expected := "ok   *dryrun.appConfig\n" +
+		"FAIL *dryrun.dbConfig\n" +
+		"     SALOME_DRY_RUN_PORT (Port): failed on the \"min=1\" rule\n"
+	if out.String() != expected {
+		t.Errorf("expecting %q, got %q", expected, out.String())
+	}
+
+	jsonFile := filepath.Join(dir, "candidate.json")
+	if err := os.WriteFile(jsonFile, []byte(`{"SALOME_DRY_RUN_PORT": 8080, "SALOME_DRY_RUN_NAME": "app2"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err := Run(t.Context(), dynamic, []string{"-file", jsonFile, "-format", "json"}, &out); err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	var results []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("expecting valid JSON, got %v: %s", err, out.String())
+	}
+	if len(results) != 2 || results[0]["ok"] != true || results[1]["ok"] != true {
+		t.Errorf("expecting every config to be ok, got %s", out.String())
+	}
+
+	if err := Run(t.Context(), dynamic, nil, &out); err == nil || !strings.Contains(err.Error(), "-file") {
+		t.Errorf("expecting -file to be required, got %v", err)
+	}
+}
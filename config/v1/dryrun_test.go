@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+type dryRunDBConfig struct {
+	Port int `env:"SALOME_DRY_RUN_PORT" validate:"min=1,max=65535"`
+}
+
+type dryRunAppConfig struct {
+	Name string `env:"SALOME_DRY_RUN_NAME,required"`
+}
+
+func newDryRunDynamic(t *testing.T) *Dynamic {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	providerMock := NewMockProvider(ctrl)
+	providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{
+		"SALOME_DRY_RUN_PORT": "5432",
+		"SALOME_DRY_RUN_NAME": "app",
+	}, nil)
+
+	dynamic, err := NewDynamic(providerMock,
+		WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})),
+		WithLoaderOptions(WithLookupOrder(LookupProviderOnly)),
+	)
+	if err != nil {
+		t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+	}
+
+	if err := dynamic.RegisterConfig(&dryRunDBConfig{}, func() any { return &dryRunDBConfig{} }); err != nil {
+		t.Fatalf("expecting nil error when registering, got %v", err)
+	}
+	if err := dynamic.RegisterConfig(&dryRunAppConfig{}, func() any { return &dryRunAppConfig{} }); err != nil {
+		t.Fatalf("expecting nil error when registering, got %v", err)
+	}
+
+	return dynamic
+}
+
+func TestDynamicDryRun(t *testing.T) {
+	dynamic := newDryRunDynamic(t)
+
+	results := dynamic.DryRun(t.Context(), map[string]string{
+		"SALOME_DRY_RUN_PORT": "70000",
+	})
+
+	if len(results) != 2 {
+		t.Fatalf("expecting 2 results, got %d", len(results))
+	}
+
+	for _, r := range results {
+		var verr *ValidationError
+		if !errors.As(r.Err, &verr) || len(verr.Fields) != 1 {
+			t.Errorf("expecting a single invalid field for %s, got %v", r.Type, r.Err)
+			continue
+		}
+
+		expected := map[string]string{
+			"*config.dryRunAppConfig": "SALOME_DRY_RUN_NAME",
+			"*config.dryRunDBConfig":  "SALOME_DRY_RUN_PORT",
+		}[r.Type]
+		if verr.Fields[0].Key != expected {
+			t.Errorf("expecting %s to fail on %s, got %v", r.Type, expected, verr.Fields[0])
+		}
+	}
+
+	// the candidate is not applied
+	if value, _ := dynamic.GetRaw("SALOME_DRY_RUN_PORT"); value != "5432" {
+		t.Errorf("expecting current config to be kept, got %q", value)
+	}
+}
+
+func TestDynamicDryRunOrder(t *testing.T) {
+	dynamic := newDryRunDynamic(t)
+
+	// registered after dryRunDBConfig under another key, sorted after it despite the key
+	if err := dynamic.RegisterConfig("a-db", func() any { return &dryRunDBConfig{} }); err != nil {
+		t.Fatalf("expecting nil error when registering, got %v", err)
+	}
+
+	// the registry is unordered, repeat to catch an order that only holds by chance
+	for range 10 {
+		results := dynamic.DryRun(t.Context(), map[string]string{})
+
+		if len(results) != 3 {
+			t.Fatalf("expecting 3 results, got %d", len(results))
+		}
+		if results[0].Type != "*config.dryRunAppConfig" || results[1].Type != "*config.dryRunDBConfig" || results[2].Key != "a-db" {
+			t.Fatalf("expecting results sorted by type, then in registration order, got %v", results)
+		}
+	}
+}
+
+func TestDynamicDryRunOverlay(t *testing.T) {
+	dynamic := newDryRunDynamic(t)
+
+	results := dynamic.DryRunOverlay(t.Context(), map[string]string{
+		"SALOME_DRY_RUN_PORT": "0",
+	})
+
+	if len(results) != 2 {
+		t.Fatalf("expecting 2 results, got %d", len(results))
+	}
+	if results[0].Err != nil {
+		t.Errorf("expecting %s to keep the current name, got %v", results[0].Type, results[0].Err)
+	}
+	if results[1].Err == nil {
+		t.Errorf("expecting %s to fail on the candidate port", results[1].Type)
+	}
+}
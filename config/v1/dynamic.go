@@ -2,22 +2,47 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
-	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/puzpuzpuz/xsync/v3"
 )
 
+// ErrClosed is returned by [Dynamic.Refresh] once the [Dynamic] is closed.
+var ErrClosed = errors.New("config: dynamic closed")
+
 // DynamicConfig holds configuration for a Dynamic instance.
 type DynamicConfig struct {
 	FetchInterval time.Duration
 	FetchTimeout  time.Duration
 	// ErrCallback will be called (if any) in case there is any error in the background process.
-	// It should not block for too long.
+	// It should not block for too long, nor call [Dynamic.Close] synchronously.
 	ErrCallback func(err error)
+	// SharedProvider prevents Close from closing the provider.
+	// It should be set when the provider is owned by something else than the Dynamic instance.
+	SharedProvider bool
+	// LoaderOptions customize how registered configs are parsed and validated.
+	LoaderOptions []LoaderOption
+	// AuditSink receives an event whenever the fetched config changes. Defaults to [LogAuditSink].
+	AuditSink AuditSink
+	// AuditHashKey is the key of the hashes of the changed values in change events, see [HashValue].
+	// Values aren't hashed without a key.
+	AuditHashKey []byte
+	// ProviderName identifies the provider in change events. Defaults to its Go type, e.g. "*dotenv.ConfigProvider".
+	ProviderName string
+	// SettlePeriod is how long a fetched change must stay the same before it is applied.
+	// Zero applies changes on the first fetch.
+	SettlePeriod time.Duration
+	// CallbackInterval is the minimum time between two callback rounds of a registrant.
+	// Zero fires callbacks on every change.
+	CallbackInterval time.Duration
+	// ChangeHistory is how many change events are kept for [Dynamic.Status]. Defaults to 20.
+	ChangeHistory int
 }
 
 type DynamicConfigOption func(*DynamicConfig)
@@ -46,10 +71,96 @@ func WithErrCallback(cb func(error)) DynamicConfigOption {
 	}
 }
 
+// WithSharedProvider marks the provider as shared, so Close leaves it open.
+// By default, Close also closes the provider if it implements [io.Closer] or [ContextCloser].
+func WithSharedProvider() DynamicConfigOption {
+	return func(dc *DynamicConfig) {
+		dc.SharedProvider = true
+	}
+}
+
+// WithLoaderOptions sets the options used to parse and validate every registered config,
+// e.g. custom validations. See [LoaderOption].
+func WithLoaderOptions(opts ...LoaderOption) DynamicConfigOption {
+	return func(dc *DynamicConfig) {
+		dc.LoaderOptions = append(dc.LoaderOptions, opts...)
+	}
+}
+
+// WithAuditSink sets the sink receiving config change events. Defaults to [LogAuditSink].
+func WithAuditSink(sink AuditSink) DynamicConfigOption {
+	return func(dc *DynamicConfig) {
+		dc.AuditSink = sink
+	}
+}
+
+// WithAuditHashKey includes the hashes of the old and new values of the changed keys in change events,
+// keyed with key (see [HashValue]), so a change can be matched to a known value by whoever has the key.
+// The key should be a random secret of at least 32 bytes, kept out of the config it hashes.
+func WithAuditHashKey(key []byte) DynamicConfigOption {
+	return func(dc *DynamicConfig) {
+		dc.AuditHashKey = key
+	}
+}
+
+// WithProviderName sets the name of the provider reported in config change events.
+func WithProviderName(name string) DynamicConfigOption {
+	return func(dc *DynamicConfig) {
+		dc.ProviderName = name
+	}
+}
+
+// WithSettlePeriod requires a fetched change to stay the same for the given period before it is applied,
+// so edits of several keys made one after another are applied at once. As changes are only seen on fetch,
+// a change is applied on the first fetch after the period elapsed. This includes the fetches of
+// [Dynamic.Refresh], e.g. from a webhook; [Dynamic.ForceRefresh] applies a change immediately.
+func WithSettlePeriod(period time.Duration) DynamicConfigOption {
+	return func(dc *DynamicConfig) {
+		dc.SettlePeriod = period
+	}
+}
+
+// WithCallbackInterval caps how often the callbacks of a registrant fire. Changes within the interval
+// are coalesced: the callbacks fire once with the latest config, on the first fetch after the interval elapsed,
+// and not at all if the config changed back to the one they were last called with.
+// [Dynamic.GetConfig] always returns the latest config.
+func WithCallbackInterval(interval time.Duration) DynamicConfigOption {
+	return func(dc *DynamicConfig) {
+		dc.CallbackInterval = interval
+	}
+}
+
+// WithChangeHistory sets how many change events are kept for [Dynamic.Status]. Defaults to 20.
+func WithChangeHistory(n int) DynamicConfigOption {
+	return func(dc *DynamicConfig) {
+		dc.ChangeHistory = n
+	}
+}
+
 type registrant[T any] struct {
 	factory    func() T
 	currentCfg T
 	callbacks  []func(any)
+	// keys are the keys looked up when loading the config, only changes of those keys reload it.
+	keys map[string]bool
+	// order is the registration order of the registrant.
+	order uint64
+
+	// notifiedCfg is the config callbacks were last called with, at notifiedAt.
+	notifiedCfg T
+	notifiedAt  time.Time
+	// pendingNotify reports whether callbacks have been held back by the callback interval.
+	pendingNotify bool
+}
+
+// affectedBy reports whether any of the changed keys is used by the registrant.
+func (r registrant[T]) affectedBy(changes []RawChange) bool {
+	for _, c := range changes {
+		if r.keys[c.Key] {
+			return true
+		}
+	}
+	return false
 }
 
 // Dynamic periodically fetches config from a Provider and keeps registered configs up to date.
@@ -66,11 +177,38 @@ type Dynamic struct {
 	// key is T{}, value is current config
 	configRegistry *xsync.MapOf[any, registrant[any]]
 	currentCfg     map[string]string
+	generation     uint64
 
-	provider Provider
+	// the change waiting for the settle period, since settleSince
+	settlingCfg map[string]string
+	settleSince time.Time
 
-	closeOnce sync.Once
-	closeCh   chan struct{}
+	// serializes update cycles of the polling loop and forced refreshes
+	updateMu sync.Mutex
+
+	lastFetch     time.Time
+	fetchFailures int // consecutive failed fetches
+	lastFetchErr  error
+	lastErr       error
+	lastErrOp     string // "fetch" or "load", the operation failing with lastErr
+	lastErrAt     time.Time
+	history       []ChangeEvent
+
+	watchMu     sync.RWMutex
+	watches     map[int]rawWatch
+	nextWatchID int
+
+	provider Provider
+	loader   *loader
+	// registrations counts the registered configs, see registrant.order
+	registrations atomic.Uint64
+
+	closeOnce         sync.Once
+	closeCh           chan struct{}
+	stoppedCh         chan struct{} // closed once the polling loop and in-flight updates are done
+	closeProviderOnce sync.Once
+	closeErr          error
+	loopWg            sync.WaitGroup
 }
 
 // NewDynamic creates a Dynamic and performs an initial config fetch from the provider.
@@ -81,11 +219,24 @@ func NewDynamic(provider Provider, opts ...DynamicConfigOption) (*Dynamic, error
 	opt := DynamicConfig{
 		FetchInterval: 10 * time.Second,
 		FetchTimeout:  5 * time.Second,
+		ChangeHistory: 20,
 	}
 	for _, optFn := range opts {
 		optFn(&opt)
 	}
 
+	if opt.AuditSink == nil {
+		opt.AuditSink = LogAuditSink()
+	}
+	if opt.ProviderName == "" {
+		opt.ProviderName = fmt.Sprintf("%T", provider)
+	}
+
+	l, err := newLoader(opt.LoaderOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("newLoader: %w", err)
+	}
+
 	currentCfg, err := provider.Config(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("provider.Config: %w", err)
@@ -94,9 +245,12 @@ func NewDynamic(provider Provider, opts ...DynamicConfigOption) (*Dynamic, error
 	d := &Dynamic{
 		configRegistry: xsync.NewMapOf[any, registrant[any]](),
 		provider:       provider,
+		loader:         l,
 		closeCh:        make(chan struct{}),
+		stoppedCh:      make(chan struct{}),
 		cfg:            opt,
 		currentCfg:     currentCfg,
+		lastFetch:      time.Now(),
 	}
 
 	return d, nil
@@ -104,6 +258,7 @@ func NewDynamic(provider Provider, opts ...DynamicConfigOption) (*Dynamic, error
 
 func (d *Dynamic) fetchConfigPeriodically() {
 	ticker := time.NewTicker(d.cfg.FetchInterval)
+	defer ticker.Stop()
 
 	for {
 		select {
@@ -112,48 +267,112 @@ func (d *Dynamic) fetchConfigPeriodically() {
 		case <-ticker.C:
 		}
 
-		d.updateConfig()
+		// errors are reported to the ErrCallback
+		_, _ = d.updateConfig(context.Background(), false)
 	}
 }
 
-func (d *Dynamic) updateConfig() {
-	ctx, cancel := context.WithTimeout(context.TODO(), d.cfg.FetchTimeout)
+// updateConfig fetches and applies the config, serialized by d.updateMu. If force is set, a change is
+// applied without waiting for the settle period. It reports whether a change was applied, and returns
+// the fetch error or the errors of the registrants that couldn't be reloaded.
+func (d *Dynamic) updateConfig(ctx context.Context, force bool) (bool, error) {
+	d.updateMu.Lock()
+	defer d.updateMu.Unlock()
+
+	select {
+	case <-d.closeCh:
+		return false, ErrClosed
+	default:
+	}
+
+	defer d.flushCallbacks()
+
+	fetchCtx, cancel := context.WithTimeout(ctx, d.cfg.FetchTimeout)
 	defer cancel()
 
-	cfgMap, err := d.provider.FetchConfig(ctx)
+	cfgMap, err := d.provider.FetchConfig(fetchCtx)
 	if err != nil {
-		if d.cfg.ErrCallback != nil {
-			d.cfg.ErrCallback(fmt.Errorf("updateConfig: d.provider.FetchConfig: %w", err))
-		}
-		return
+		err = fmt.Errorf("updateConfig: d.provider.FetchConfig: %w", err)
+
+		d.mu.Lock()
+		d.fetchFailures++
+		d.lastFetchErr = err
+		d.mu.Unlock()
+
+		d.reportErr("fetch", err)
+		return false, err
 	}
 
 	d.mu.Lock()
+	d.lastFetch = time.Now()
+	d.fetchFailures = 0
+	d.lastFetchErr = nil
 	if maps.Equal(d.currentCfg, cfgMap) {
+		d.settlingCfg = nil
 		d.mu.Unlock()
-		return
+		return false, nil
+	}
+
+	if force {
+		d.settlingCfg = nil
+	} else if !d.settled(cfgMap) {
+		d.mu.Unlock()
+		return false, nil
+	}
+
+	changes := diffRaw(d.currentCfg, cfgMap)
+	added, removed, modified := diffConfig(changes, d.cfg.AuditHashKey)
+	d.generation++
+	event := ChangeEvent{
+		Generation: d.generation,
+		Time:       time.Now(),
+		Provider:   d.cfg.ProviderName,
+		Added:      added,
+		Removed:    removed,
+		Modified:   modified,
 	}
 
 	d.currentCfg = cfgMap
+	if d.cfg.ChangeHistory > 0 {
+		d.history = append(d.history, event)
+		if len(d.history) > d.cfg.ChangeHistory {
+			d.history = slices.Delete(d.history, 0, len(d.history)-d.cfg.ChangeHistory)
+		}
+	}
 	d.mu.Unlock()
 
+	d.cfg.AuditSink.Audit(ctx, event)
+
+	var errs []error
 	d.configRegistry.Range(func(key any, value registrant[any]) bool {
+		if !value.affectedBy(changes) {
+			return true
+		}
+
 		dst := value.factory()
+		keys := make(map[string]bool, len(value.keys))
 
-		err := loadConfigFromMapTo(context.TODO(), dst, cfgMap)
+		err := d.loader.loadConfigFromMapToTracking(ctx, dst, cfgMap, keys)
 		if err != nil {
-			if d.cfg.ErrCallback != nil {
-				d.cfg.ErrCallback(fmt.Errorf("updateConfig: loadConfigFromMapTo: %w", err))
-			}
+			err = fmt.Errorf("updateConfig: d.loader.loadConfigFromMapToTracking: %w", err)
+			errs = append(errs, err)
+			d.reportErr("load", err)
 			return true
 		}
 
 		old := value.currentCfg
 		value.currentCfg = dst
+		value.keys = keys
 
+		if Equal(old, dst) {
+			d.configRegistry.Store(key, value)
+			return true
+		}
+
+		notify := d.shouldNotify(&value)
 		d.configRegistry.Store(key, value)
 
-		if !reflect.DeepEqual(old, dst) {
+		if notify {
 			for _, cb := range value.callbacks {
 				cb(dst)
 			}
@@ -161,29 +380,113 @@ func (d *Dynamic) updateConfig() {
 
 		return true
 	})
+
+	d.notifyWatches(changes)
+
+	return true, errors.Join(errs...)
 }
 
-// RegisterConfig registers a key and factory for dynamic config updates.
-// Factory must return a pointer to a zero config struct used for parsing.
-// The parsed config is immediately available via GetConfig.
-func (d *Dynamic) RegisterConfig(key any, factory func() any) error {
-	dst := factory()
+// reportErr records the error of the background process for [Dynamic.Status] and passes it to the ErrCallback.
+func (d *Dynamic) reportErr(op string, err error) {
+	d.mu.Lock()
+	d.lastErr = err
+	d.lastErrOp = op
+	d.lastErrAt = time.Now()
+	d.mu.Unlock()
 
-	d.mu.RLock()
-	currentCfg := d.currentCfg
-	d.mu.RUnlock()
+	if d.cfg.ErrCallback != nil {
+		d.cfg.ErrCallback(err)
+	}
+}
 
-	err := loadConfigFromMapTo(context.TODO(), dst, currentCfg)
-	if err != nil {
-		return fmt.Errorf("loadConfigFromMapTo: %w", err)
+// Refresh fetches and applies the config from the provider now instead of waiting for the next poll,
+// e.g. on SIGHUP or from a webhook. It's serialized with the polling loop and honors the settle period:
+// a change seen for the first time isn't applied until a fetch after the period, see [Dynamic.ForceRefresh].
+//
+// It reports whether a change was applied, and returns the fetch error or the errors of the configs that
+// couldn't be reloaded. Errors are also reported to the ErrCallback like the ones of the polling loop.
+// It returns [ErrClosed] once d is closed.
+func (d *Dynamic) Refresh(ctx context.Context) (changed bool, err error) {
+	return d.updateConfig(ctx, false)
+}
+
+// ForceRefresh is [Dynamic.Refresh] bypassing the settle period: a fetched change is applied right away,
+// e.g. when an operator or a webhook reports that an edit is complete.
+func (d *Dynamic) ForceRefresh(ctx context.Context) (changed bool, err error) {
+	return d.updateConfig(ctx, true)
+}
+
+// settled reports whether the fetched change has stayed the same for the settle period.
+// It must be called with d.mu held.
+func (d *Dynamic) settled(cfgMap map[string]string) bool {
+	if d.cfg.SettlePeriod <= 0 {
+		return true
 	}
 
-	d.configRegistry.Store(key, registrant[any]{
-		factory:    factory,
-		currentCfg: dst,
+	now := time.Now()
+	if d.settlingCfg == nil || !maps.Equal(d.settlingCfg, cfgMap) {
+		d.settlingCfg = cfgMap
+		d.settleSince = now
+	}
+
+	if now.Sub(d.settleSince) < d.cfg.SettlePeriod {
+		return false
+	}
+
+	d.settlingCfg = nil
+	return true
+}
+
+// shouldNotify reports whether the callbacks of the changed registrant can fire now,
+// otherwise they're marked as pending.
+func (d *Dynamic) shouldNotify(value *registrant[any]) bool {
+	now := time.Now()
+	if d.cfg.CallbackInterval > 0 && !value.notifiedAt.IsZero() && now.Sub(value.notifiedAt) < d.cfg.CallbackInterval {
+		value.pendingNotify = true
+		return false
+	}
+
+	value.notifiedCfg = value.currentCfg
+	value.notifiedAt = now
+	value.pendingNotify = false
+	return true
+}
+
+// flushCallbacks fires the callbacks held back by the callback interval once it elapsed.
+func (d *Dynamic) flushCallbacks() {
+	if d.cfg.CallbackInterval <= 0 {
+		return
+	}
+
+	now := time.Now()
+	d.configRegistry.Range(func(key any, value registrant[any]) bool {
+		if !value.pendingNotify || now.Sub(value.notifiedAt) < d.cfg.CallbackInterval {
+			return true
+		}
+
+		value.pendingNotify = false
+		if Equal(value.notifiedCfg, value.currentCfg) {
+			d.configRegistry.Store(key, value)
+			return true
+		}
+
+		value.notifiedCfg = value.currentCfg
+		value.notifiedAt = now
+		d.configRegistry.Store(key, value)
+
+		for _, cb := range value.callbacks {
+			cb(value.currentCfg)
+		}
+		return true
 	})
+}
 
-	return nil
+// RegisterConfig registers a key and factory for dynamic config updates.
+// Factory must return a pointer to a zero config struct used for parsing.
+// The parsed config is immediately available via GetConfig.
+// It waits for an update cycle in progress, so it must not be called from a callback.
+func (d *Dynamic) RegisterConfig(key any, factory func() any) error {
+	return d.register(key, factory)
 }
 
 // RegisterConfigWithNotify is like RegisterConfig but also returns a callback adder.
@@ -191,22 +494,10 @@ func (d *Dynamic) RegisterConfig(key any, factory func() any) error {
 // the config changes. Callbacks are called synchronously during the update cycle, so
 // they must not block for too long.
 func (d *Dynamic) RegisterConfigWithNotify(key any, factory func() any) (CallbackAdder, error) {
-	dst := factory()
-
-	d.mu.RLock()
-	currentCfg := d.currentCfg
-	d.mu.RUnlock()
-
-	err := loadConfigFromMapTo(context.TODO(), dst, currentCfg)
-	if err != nil {
-		return nil, fmt.Errorf("loadConfigFromMapTo: %w", err)
+	if err := d.register(key, factory); err != nil {
+		return nil, err
 	}
 
-	d.configRegistry.Store(key, registrant[any]{
-		factory:    factory,
-		currentCfg: dst,
-	})
-
 	adder := callbackAdderFunc(func(cb func(any)) {
 		d.configRegistry.Compute(key, func(oldValue registrant[any], loaded bool) (newValue registrant[any], delete bool) {
 			if !loaded {
@@ -221,6 +512,35 @@ func (d *Dynamic) RegisterConfigWithNotify(key any, factory func() any) (Callbac
 	return adder, nil
 }
 
+// register loads the config from the current one and stores its registrant. It holds d.updateMu, so an
+// update can't apply a config the registrant wasn't loaded from, and then skip it.
+func (d *Dynamic) register(key any, factory func() any) error {
+	d.updateMu.Lock()
+	defer d.updateMu.Unlock()
+
+	dst := factory()
+
+	d.mu.RLock()
+	currentCfg := d.currentCfg
+	d.mu.RUnlock()
+
+	keys := make(map[string]bool)
+	err := d.loader.loadConfigFromMapToTracking(context.TODO(), dst, currentCfg, keys)
+	if err != nil {
+		return fmt.Errorf("d.loader.loadConfigFromMapToTracking: %w", err)
+	}
+
+	d.configRegistry.Store(key, registrant[any]{
+		factory:     factory,
+		currentCfg:  dst,
+		keys:        keys,
+		order:       d.registrations.Add(1),
+		notifiedCfg: dst,
+	})
+
+	return nil
+}
+
 // GetConfig returns the latest parsed config for the given key, or nil if not registered.
 func (d *Dynamic) GetConfig(key any) any {
 	cfg, ok := d.configRegistry.Load(key)
@@ -230,14 +550,68 @@ func (d *Dynamic) GetConfig(key any) any {
 	return cfg.currentCfg
 }
 
+// Explain reports the source of every field of the config registered with the given key
+// (see [ExplainConfig]). Sources are resolved against the latest config fetched from the provider.
+func (d *Dynamic) Explain(key any) ([]FieldSource, error) {
+	value, ok := d.configRegistry.Load(key)
+	if !ok {
+		return nil, fmt.Errorf("config: key %v is not registered", key)
+	}
+
+	d.mu.RLock()
+	currentCfg := d.currentCfg
+	d.mu.RUnlock()
+
+	sources, err := d.loader.explain(value.currentCfg, currentCfg, d.provider)
+	if err != nil {
+		return nil, fmt.Errorf("d.loader.explain: %w", err)
+	}
+
+	return sources, nil
+}
+
 // Start begins the background polling loop. Must be called once after NewDynamic.
 func (d *Dynamic) Start() {
-	go d.fetchConfigPeriodically()
+	d.loopWg.Go(d.fetchConfigPeriodically)
 }
 
-// Close stops the background polling loop. Safe to call multiple times.
-func (d *Dynamic) Close() {
+// Close stops the background polling loop and waits for it to exit, along with an in-flight [Dynamic.Refresh].
+// Unless [WithSharedProvider] is used, the provider is then closed as well with ctx (see [CloseProvider]).
+//
+// Callbacks and the ErrCallback are called by the polling loop or Refresh, so Close can't finish while
+// one of them runs: calling it from a callback waits until ctx is done and returns its error. Call it in
+// a new goroutine instead, e.g. go d.Close(context.Background()).
+//
+// Safe to call multiple times. Once the loop has exited, subsequent calls return the result of the first
+// one closing the provider.
+func (d *Dynamic) Close(ctx context.Context) error {
 	d.closeOnce.Do(func() {
 		close(d.closeCh)
+
+		go func() {
+			d.loopWg.Wait()
+
+			// wait for an in-flight Refresh, later ones fail with ErrClosed
+			d.updateMu.Lock()
+			close(d.stoppedCh)
+			d.updateMu.Unlock()
+		}()
+	})
+
+	select {
+	case <-d.stoppedCh:
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for the polling loop: %w", ctx.Err())
+	}
+
+	d.closeProviderOnce.Do(func() {
+		if d.cfg.SharedProvider {
+			return
+		}
+
+		if err := CloseProvider(ctx, d.provider); err != nil {
+			d.closeErr = fmt.Errorf("CloseProvider: %w", err)
+		}
 	})
+	return d.closeErr
 }
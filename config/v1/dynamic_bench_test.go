@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"testing"
+)
+
+// alternatingProvider returns a different config on every fetch.
+type alternatingProvider struct {
+	cfgs [2]map[string]string
+	n    int
+}
+
+func (p *alternatingProvider) Config(context.Context) (map[string]string, error) {
+	return maps.Clone(p.cfgs[0]), nil
+}
+
+func (p *alternatingProvider) FetchConfig(context.Context) (map[string]string, error) {
+	p.n++
+	return maps.Clone(p.cfgs[p.n%2]), nil
+}
+
+type benchConfig struct {
+	Host    string `env:"HOST" validate:"required"`
+	Port    int    `env:"PORT" validate:"min=1"`
+	Enabled bool   `env:"ENABLED"`
+}
+
+// BenchmarkDynamicUpdateConfig measures an update cycle with many registrants, when a single
+// registrant is affected by the change and when all of them are.
+func BenchmarkDynamicUpdateConfig(b *testing.B) {
+	const registrants = 200
+
+	for _, changed := range []int{1, registrants} {
+		b.Run(fmt.Sprintf("registrants=%d/changed=%d", registrants, changed), func(b *testing.B) {
+			provider := &alternatingProvider{cfgs: [2]map[string]string{{}, {}}}
+			for i := range registrants {
+				prefix := fmt.Sprintf("SALOME_BENCH_%d_", i)
+				for _, cfg := range provider.cfgs {
+					cfg[prefix+"HOST"] = "localhost"
+					cfg[prefix+"ENABLED"] = "true"
+				}
+
+				provider.cfgs[0][prefix+"PORT"] = "8080"
+				provider.cfgs[1][prefix+"PORT"] = "8080"
+				if i < changed {
+					provider.cfgs[1][prefix+"PORT"] = "9090"
+				}
+			}
+
+			dynamic, err := NewDynamic(provider,
+				WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})),
+				WithLoaderOptions(WithLookupOrder(LookupProviderOnly)),
+			)
+			if err != nil {
+				b.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+			}
+
+			for i := range registrants {
+				if err := dynamic.RegisterConfig(i, benchFactory(i)); err != nil {
+					b.Fatalf("expecting nil error when registering, got %v", err)
+				}
+			}
+
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = dynamic.updateConfig(context.Background(), false)
+			}
+		})
+	}
+}
+
+// benchFactory returns the factory of a struct embedding benchConfig with a prefix unique to i.
+func benchFactory(i int) func() any {
+	t := reflect.StructOf([]reflect.StructField{{
+		Name: "Config",
+		Type: reflect.TypeFor[benchConfig](),
+		Tag:  reflect.StructTag(fmt.Sprintf(`env:",prefix=SALOME_BENCH_%d_"`, i)),
+	}})
+
+	return func() any {
+		return reflect.New(t).Interface()
+	}
+}
@@ -1,11 +1,18 @@
 package config
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"testing/synctest"
 	"time"
 
+	"github.com/go-playground/validator/v10"
+	"github.com/sethvargo/go-envconfig"
 	"go.uber.org/mock/gomock"
 )
 
@@ -38,7 +45,7 @@ func TestDynamic(t *testing.T) {
 			}
 
 			dynamic.Start()
-			defer dynamic.Close()
+			defer dynamic.Close(t.Context())
 
 			cfg1 := Config1{}
 			adder, err := dynamic.RegisterConfigWithNotify(&cfg1, func() any {
@@ -96,7 +103,7 @@ func TestDynamic(t *testing.T) {
 			}
 
 			dynamic.Start()
-			defer dynamic.Close()
+			defer dynamic.Close(t.Context())
 
 			cfg1 := Config1{}
 			adder, err := dynamic.RegisterConfigWithNotify(&cfg1, func() any {
@@ -144,7 +151,7 @@ func TestDynamic(t *testing.T) {
 			}
 
 			dynamic.Start()
-			defer dynamic.Close()
+			defer dynamic.Close(t.Context())
 
 			cfg1 := Config1{}
 			cfg2 := Config2{}
@@ -185,3 +192,505 @@ func TestDynamic(t *testing.T) {
 		})
 	})
 }
+
+func TestDynamicWithLoaderOptions(t *testing.T) {
+	type Config struct {
+		Region string `env:"REGION" validate:"region"`
+	}
+
+	ctrl := gomock.NewController(t)
+	providerMock := NewMockProvider(ctrl)
+	providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{
+		"REGION": "us-east-1",
+	}, nil)
+
+	dynamic, err := NewDynamic(providerMock, WithLoaderOptions(
+		WithValidation("region", func(_ context.Context, fl validator.FieldLevel) bool {
+			return fl.Field().String() == "ap-southeast-1"
+		}),
+	))
+	if err != nil {
+		t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+	}
+
+	err = dynamic.RegisterConfig(&Config{}, func() any { return &Config{} })
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Fields[0].Rule != "region" {
+		t.Errorf("expecting region validation error, got %v", err)
+	}
+}
+
+type closableProvider struct {
+	*MockProvider
+	*MockContextCloser
+}
+
+func TestDynamicClose(t *testing.T) {
+	t.Run("closes provider implementing ContextCloser", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+		closerMock := NewMockContextCloser(ctrl)
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{}, nil)
+		closerMock.EXPECT().Close(gomock.Any()).Return(errors.New("close failed")).Times(1)
+
+		dynamic, err := NewDynamic(closableProvider{providerMock, closerMock})
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+
+		dynamic.Start()
+
+		if err := dynamic.Close(t.Context()); err == nil {
+			t.Errorf("expecting close error to be reported, got nil")
+		}
+
+		// second call must not close the provider again
+		if err := dynamic.Close(t.Context()); err == nil {
+			t.Errorf("expecting close error to be reported on subsequent call, got nil")
+		}
+	})
+
+	t.Run("closes provider with the context", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+		closerMock := NewMockContextCloser(ctrl)
+
+		type ctxKey struct{}
+		ctx := context.WithValue(t.Context(), ctxKey{}, "close")
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{}, nil)
+		closerMock.EXPECT().Close(ctx).Return(nil).Times(1)
+
+		dynamic, err := NewDynamic(closableProvider{providerMock, closerMock})
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+
+		if err := dynamic.Close(ctx); err != nil {
+			t.Errorf("expecting nil error, got %v", err)
+		}
+	})
+
+	t.Run("from a callback", func(t *testing.T) {
+		type Config struct {
+			Test string `env:"SALOME_CLOSE_CALLBACK"`
+		}
+
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+		closerMock := NewMockContextCloser(ctrl)
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{}, nil)
+		providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{"SALOME_CLOSE_CALLBACK": "changed"}, nil)
+		closerMock.EXPECT().Close(gomock.Any()).Return(nil).Times(1)
+
+		dynamic, err := NewDynamic(closableProvider{providerMock, closerMock},
+			WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})),
+			WithLoaderOptions(WithLookupOrder(LookupProviderOnly)),
+		)
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+
+		getter, err := LoadDynamicConfigToWithNotify[Config](dynamic)
+		if err != nil {
+			t.Fatalf("expecting nil error when registering, got %v", err)
+		}
+
+		var callbackErr error
+		getter.RegisterCallback(func(Config) {
+			// the Refresh calling the callback can't finish before it returns
+			ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+			defer cancel()
+
+			callbackErr = dynamic.Close(ctx)
+		})
+
+		if _, err := dynamic.Refresh(t.Context()); err != nil {
+			t.Fatalf("expecting nil error when refreshing, got %v", err)
+		}
+		if !errors.Is(callbackErr, context.DeadlineExceeded) {
+			t.Errorf("expecting Close to give up with the context, got %v", callbackErr)
+		}
+
+		if err := dynamic.Close(t.Context()); err != nil {
+			t.Errorf("expecting nil error once the callback returned, got %v", err)
+		}
+	})
+
+	t.Run("keeps shared provider open", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+		closerMock := NewMockContextCloser(ctrl)
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{}, nil)
+		closerMock.EXPECT().Close(gomock.Any()).Times(0)
+
+		dynamic, err := NewDynamic(closableProvider{providerMock, closerMock}, WithSharedProvider())
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+
+		if err := dynamic.Close(t.Context()); err != nil {
+			t.Errorf("expecting nil error, got %v", err)
+		}
+	})
+}
+
+func TestDynamicReloadsAffectedRegistrantsOnly(t *testing.T) {
+	type Config1 struct {
+		Test string `env:"SALOME_AFFECTED_TEST1"`
+	}
+
+	type Config2 struct {
+		// the default references another key, which must be tracked as well
+		Test string `env:"SALOME_AFFECTED_TEST2,default=$SALOME_AFFECTED_BASE"`
+	}
+
+	ctrl := gomock.NewController(t)
+	providerMock := NewMockProvider(ctrl)
+	providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{
+		"SALOME_AFFECTED_TEST1": "a",
+		"SALOME_AFFECTED_BASE":  "b",
+	}, nil)
+	gomock.InOrder(
+		providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+			"SALOME_AFFECTED_TEST1": "a1",
+			"SALOME_AFFECTED_BASE":  "b",
+		}, nil),
+		providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+			"SALOME_AFFECTED_TEST1": "a1",
+			"SALOME_AFFECTED_BASE":  "b1",
+		}, nil),
+	)
+
+	dynamic, err := NewDynamic(providerMock, WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})))
+	if err != nil {
+		t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+	}
+
+	var loads1, loads2 int
+	if err := dynamic.RegisterConfig(&Config1{}, func() any { loads1++; return &Config1{} }); err != nil {
+		t.Fatalf("expecting nil error when registering Config1, got %v", err)
+	}
+	key2 := &Config2{}
+	if err := dynamic.RegisterConfig(key2, func() any { loads2++; return &Config2{} }); err != nil {
+		t.Fatalf("expecting nil error when registering Config2, got %v", err)
+	}
+
+	if _, err := dynamic.Refresh(t.Context()); err != nil {
+		t.Fatalf("expecting nil error when refreshing, got %v", err)
+	}
+
+	if loads1 != 2 || loads2 != 1 {
+		t.Errorf("expecting only Config1 to be reloaded, got %d and %d loads", loads1, loads2)
+	}
+
+	if _, err := dynamic.Refresh(t.Context()); err != nil {
+		t.Fatalf("expecting nil error when refreshing, got %v", err)
+	}
+
+	if loads1 != 2 || loads2 != 2 {
+		t.Errorf("expecting only Config2 to be reloaded, got %d and %d loads", loads1, loads2)
+	}
+	if got := dynamic.GetConfig(key2).(*Config2).Test; got != "b1" {
+		t.Errorf("expecting default to be expanded from the changed key, got %q", got)
+	}
+}
+
+func TestDynamicRefresh(t *testing.T) {
+	type Config struct {
+		Port int `env:"SALOME_REFRESH_PORT"`
+	}
+
+	ctrl := gomock.NewController(t)
+	providerMock := NewMockProvider(ctrl)
+	providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{"SALOME_REFRESH_PORT": "80"}, nil)
+	gomock.InOrder(
+		providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{"SALOME_REFRESH_PORT": "80"}, nil),
+		providerMock.EXPECT().FetchConfig(gomock.Any()).Return(nil, errors.New("unavailable")),
+		providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{"SALOME_REFRESH_PORT": "eighty"}, nil),
+		providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{"SALOME_REFRESH_PORT": "8080"}, nil),
+	)
+
+	var reported []error
+	dynamic, err := NewDynamic(providerMock,
+		WithErrCallback(func(err error) { reported = append(reported, err) }),
+		WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})),
+	)
+	if err != nil {
+		t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+	}
+
+	key := &Config{}
+	if err := dynamic.RegisterConfig(key, func() any { return &Config{} }); err != nil {
+		t.Fatalf("expecting nil error when registering, got %v", err)
+	}
+
+	if changed, err := dynamic.Refresh(t.Context()); changed || err != nil {
+		t.Errorf("expecting no change and nil error, got %v and %v", changed, err)
+	}
+
+	if changed, err := dynamic.Refresh(t.Context()); changed || err == nil {
+		t.Errorf("expecting no change and the fetch error, got %v and %v", changed, err)
+	}
+
+	if changed, err := dynamic.Refresh(t.Context()); !changed || err == nil {
+		t.Errorf("expecting a change and the parse error, got %v and %v", changed, err)
+	}
+	if got := dynamic.GetConfig(key).(*Config).Port; got != 80 {
+		t.Errorf("expecting the config to be kept on parse error, got %d", got)
+	}
+
+	if changed, err := dynamic.Refresh(t.Context()); !changed || err != nil {
+		t.Errorf("expecting a change and nil error, got %v and %v", changed, err)
+	}
+	if got := dynamic.GetConfig(key).(*Config).Port; got != 8080 {
+		t.Errorf("expecting the refreshed config, got %d", got)
+	}
+
+	if len(reported) != 2 {
+		t.Errorf("expecting errors to be reported to the callback as well, got %v", reported)
+	}
+
+	if err := dynamic.Close(t.Context()); err != nil {
+		t.Fatalf("expecting nil error when closing, got %v", err)
+	}
+	if _, err := dynamic.Refresh(t.Context()); !errors.Is(err, ErrClosed) {
+		t.Errorf("expecting ErrClosed after closing, got %v", err)
+	}
+}
+
+func TestDynamicSettlePeriod(t *testing.T) {
+	type Config struct {
+		User     string `env:"SALOME_SETTLE_USER"`
+		Password string `env:"SALOME_SETTLE_PASSWORD"`
+	}
+
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{
+			"SALOME_SETTLE_USER":     "a",
+			"SALOME_SETTLE_PASSWORD": "a",
+		}, nil)
+		gomock.InOrder(
+			// the operator edits one key, then the other
+			providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+				"SALOME_SETTLE_USER":     "b",
+				"SALOME_SETTLE_PASSWORD": "a",
+			}, nil),
+			providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+				"SALOME_SETTLE_USER":     "b",
+				"SALOME_SETTLE_PASSWORD": "b",
+			}, nil).AnyTimes(),
+		)
+
+		dynamic, err := NewDynamic(providerMock,
+			WithSettlePeriod(15*time.Second),
+			WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})),
+		)
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+
+		key := &Config{}
+		adder, err := dynamic.RegisterConfigWithNotify(key, func() any { return &Config{} })
+		if err != nil {
+			t.Fatalf("expecting nil error when registering, got %v", err)
+		}
+
+		var (
+			mu     sync.Mutex
+			values []Config
+		)
+		adder.Add(func(v any) {
+			mu.Lock()
+			defer mu.Unlock()
+			values = append(values, *v.(*Config))
+		})
+		snapshot := func() []Config {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(values)
+		}
+
+		dynamic.Start()
+		defer dynamic.Close(t.Context())
+
+		// fetched at 10s (partial) and 20s (complete), the complete change settles at 35s and is applied at 40s
+		time.Sleep(35 * time.Second)
+		if values := snapshot(); len(values) != 0 {
+			t.Errorf("expecting no change applied before settling, got %v", values)
+		}
+
+		time.Sleep(10 * time.Second)
+		if expected, values := []Config{{User: "b", Password: "b"}}, snapshot(); !reflect.DeepEqual(expected, values) {
+			t.Errorf("expecting a single settled change %v, got %v", expected, values)
+		}
+	})
+}
+
+func TestDynamicForceRefresh(t *testing.T) {
+	type Config struct {
+		Test string `env:"SALOME_FORCE_REFRESH_TEST"`
+	}
+
+	ctrl := gomock.NewController(t)
+	providerMock := NewMockProvider(ctrl)
+
+	providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{"SALOME_FORCE_REFRESH_TEST": "a"}, nil)
+	providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{"SALOME_FORCE_REFRESH_TEST": "b"}, nil).Times(2)
+
+	dynamic, err := NewDynamic(providerMock,
+		WithSettlePeriod(time.Hour),
+		WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})),
+	)
+	if err != nil {
+		t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+	}
+
+	key := &Config{}
+	if err := dynamic.RegisterConfig(key, func() any { return &Config{} }); err != nil {
+		t.Fatalf("expecting nil error when registering, got %v", err)
+	}
+
+	if changed, err := dynamic.Refresh(t.Context()); changed || err != nil {
+		t.Errorf("expecting the change to wait for the settle period, got %v and %v", changed, err)
+	}
+	if changed, err := dynamic.ForceRefresh(t.Context()); !changed || err != nil {
+		t.Errorf("expecting the change to be applied, got %v and %v", changed, err)
+	}
+	if got := dynamic.GetConfig(key).(*Config).Test; got != "b" {
+		t.Errorf("expecting the forced config, got %q", got)
+	}
+}
+
+func TestDynamicRegisterDuringUpdate(t *testing.T) {
+	type Config struct {
+		Test string `env:"SALOME_REGISTER_RACE_TEST"`
+	}
+
+	ctrl := gomock.NewController(t)
+	providerMock := NewMockProvider(ctrl)
+	providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{"SALOME_REGISTER_RACE_TEST": "a"}, nil)
+	providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{"SALOME_REGISTER_RACE_TEST": "b"}, nil)
+
+	// the first load, of the registration, is held until the refresh had the chance to run
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	hold := envconfig.MutatorFunc(func(_ context.Context, _, _, _, currentValue string) (string, bool, error) {
+		once.Do(func() {
+			close(loading)
+			<-release
+		})
+		return currentValue, false, nil
+	})
+
+	dynamic, err := NewDynamic(providerMock,
+		WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})),
+		WithLoaderOptions(WithMutators(hold)),
+	)
+	if err != nil {
+		t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+	}
+
+	key := &Config{}
+	registered := make(chan error)
+	go func() {
+		registered <- dynamic.RegisterConfig(key, func() any { return &Config{} })
+	}()
+	<-loading
+
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		_, _ = dynamic.ForceRefresh(context.Background())
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if err := <-registered; err != nil {
+		t.Fatalf("expecting nil error when registering, got %v", err)
+	}
+	<-refreshed
+
+	if got := dynamic.GetConfig(key).(*Config).Test; got != "b" {
+		t.Errorf("expecting the config applied during the registration, got %q", got)
+	}
+}
+
+func TestDynamicCallbackInterval(t *testing.T) {
+	type Config struct {
+		Test string `env:"SALOME_CALLBACK_INTERVAL_TEST"`
+	}
+
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{"SALOME_CALLBACK_INTERVAL_TEST": "0"}, nil)
+		var calls []any
+		for _, value := range []string{"1", "2", "3"} {
+			calls = append(calls, providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+				"SALOME_CALLBACK_INTERVAL_TEST": value,
+			}, nil))
+		}
+		calls = append(calls, providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+			"SALOME_CALLBACK_INTERVAL_TEST": "3",
+		}, nil).AnyTimes())
+		gomock.InOrder(calls...)
+
+		dynamic, err := NewDynamic(providerMock,
+			WithCallbackInterval(time.Minute),
+			WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})),
+		)
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+
+		key := &Config{}
+		adder, err := dynamic.RegisterConfigWithNotify(key, func() any { return &Config{} })
+		if err != nil {
+			t.Fatalf("expecting nil error when registering, got %v", err)
+		}
+
+		var (
+			mu     sync.Mutex
+			values []string
+		)
+		adder.Add(func(v any) {
+			mu.Lock()
+			defer mu.Unlock()
+			values = append(values, v.(*Config).Test)
+		})
+		snapshot := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(values)
+		}
+
+		dynamic.Start()
+		defer dynamic.Close(t.Context())
+
+		time.Sleep(35 * time.Second)
+
+		if expected, values := []string{"1"}, snapshot(); !reflect.DeepEqual(expected, values) {
+			t.Errorf("expecting callbacks to be rate-limited to %v, got %v", expected, values)
+		}
+		if got := dynamic.GetConfig(key).(*Config).Test; got != "3" {
+			t.Errorf("expecting the latest config regardless of callbacks, got %q", got)
+		}
+
+		time.Sleep(40 * time.Second)
+
+		if expected, values := []string{"1", "3"}, snapshot(); !reflect.DeepEqual(expected, values) {
+			t.Errorf("expecting intermediate states to be coalesced into %v, got %v", expected, values)
+		}
+	})
+}
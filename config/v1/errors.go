@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSecret is wrapped by the decode errors of secret fields instead of the error of the decoder,
+// which may contain the value.
+var ErrInvalidSecret = errors.New("config: invalid secret value")
+
+// ValidationError reports every config field that failed to decode or validate.
+// It is returned (wrapped) by [LoadConfigTo] and the registration methods of [Dynamic],
+// and can be retrieved with [errors.As].
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// FieldError describes a single config field that failed to decode or validate.
+type FieldError struct {
+	// Field is the dotted Go path of the field, e.g. "Database.Host".
+	Field string
+	// Key is the config key of the field. It is empty for errors not bound to a single key,
+	// e.g. struct-level validations.
+	Key string
+	// Value is the offending value. It is masked if the field is secret (see [Redacted]).
+	Value string
+	// Rule is the failed rule: the validation tag with its param (e.g. "len=3"),
+	// "required" when a required key is missing or "decode" when the value can't be parsed.
+	Rule string
+	// Message is a human readable description of the failure.
+	Message string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+const (
+	ruleRequired = "required"
+	ruleDecode   = "decode"
+)
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "config: %d invalid field(s): ", len(e.Fields))
+	for i, f := range e.Fields {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(f.String())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the underlying errors of the fields.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		if f.Err != nil {
+			errs = append(errs, f.Err)
+		}
+	}
+	return errs
+}
+
+func (f FieldError) String() string {
+	name := f.Field
+	if f.Key != "" {
+		name = fmt.Sprintf("%s (%s)", f.Key, f.Field)
+	}
+	return fmt.Sprintf("%s: %s", name, f.Message)
+}
+
+// errorFieldPath splits the field path envconfig prepends to its errors, e.g. "Database: Port: <err>".
+// It returns the path segments and the errors left after peeling each segment,
+// i.e. errs[i] is the error after the first i segments are removed.
+func errorFieldPath(err error) (path []string, errs []error) {
+	errs = append(errs, err)
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			return path, errs
+		}
+
+		prefix, ok := strings.CutSuffix(err.Error(), inner.Error())
+		if !ok {
+			return path, errs
+		}
+
+		segment, ok := strings.CutSuffix(prefix, ": ")
+		if !ok || segment == "" {
+			return path, errs
+		}
+
+		path = append(path, segment)
+		errs = append(errs, inner)
+		err = inner
+	}
+}
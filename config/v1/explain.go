@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+// Sources reported by [ExplainConfig] and [Dynamic.Explain] besides the ones annotated by providers.
+const (
+	// SourceOS is the OS environment, looked up by the loader itself (see [LookupOrder]).
+	SourceOS = "os"
+	// SourceProvider is the provider's config, when the provider doesn't implement [SourceAnnotator].
+	SourceProvider = "provider"
+	// SourceLookupChain is a lookuper of [WithLookupChain] other than the provider's.
+	SourceLookupChain = "lookup chain"
+	// SourceDefault is the `default=` option of the `env` tag.
+	SourceDefault = "default"
+)
+
+// FieldSource explains where the value of a config field came from.
+type FieldSource struct {
+	Key   string
+	Field string
+	// Value is the effective value of the field, or "******" if the field is secret.
+	// It is nil if a parent struct pointer is nil.
+	Value any
+	// Source is where the value came from, e.g. [SourceOS], [SourceDefault] or the source
+	// annotated by the provider. It is empty if the key is not set and has no default.
+	Source string
+	// Default reports whether the `default=` option of the `env` tag was used.
+	Default bool
+}
+
+// ExplainConfig loads config to T from the provider like [LoadConfigTo] and reports the source
+// of every field.
+func ExplainConfig[T any](provider Provider, opts ...LoaderOption) ([]FieldSource, error) {
+	ctx := context.TODO()
+
+	l, err := newLoader(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("newLoader: %w", err)
+	}
+
+	cfg, err := provider.Config(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("provider.Config: %w", err)
+	}
+
+	var dst T
+	if err := l.loadConfigFromMapTo(ctx, &dst, cfg); err != nil {
+		return nil, fmt.Errorf("loadConfigFromMapTo: %w", err)
+	}
+
+	sources, err := l.explain(&dst, cfg, provider)
+	if err != nil {
+		return nil, fmt.Errorf("l.explain: %w", err)
+	}
+
+	return sources, nil
+}
+
+// explain reports the source of every field of dst, which has been loaded from cfg.
+func (l *loader) explain(dst any, cfg map[string]string, provider Provider) ([]FieldSource, error) {
+	fields, err := l.configFields(reflect.TypeOf(dst))
+	if err != nil {
+		return nil, fmt.Errorf("l.configFields: %w", err)
+	}
+
+	root := reflect.ValueOf(dst)
+	for root.Kind() == reflect.Pointer {
+		if root.IsNil() {
+			return nil, fmt.Errorf("config: can't explain nil %s", root.Type())
+		}
+		root = root.Elem()
+	}
+
+	out := make([]FieldSource, 0, len(fields))
+	for _, f := range fields {
+		fs := FieldSource{
+			Key:    f.Key,
+			Field:  f.Name,
+			Value:  redactedFieldValue(f, root),
+			Source: l.source(f.Key, cfg, provider),
+		}
+
+		if fs.Source == "" && f.Tag.Default != "" {
+			fs.Source = SourceDefault
+			fs.Default = true
+		}
+
+		out = append(out, fs)
+	}
+
+	return out, nil
+}
+
+// source resolves which source of the lookup chain provides the key. It mirrors l.lookuper,
+// without reporting shadowed keys.
+func (l *loader) source(key string, cfg map[string]string, provider Provider) string {
+	providerValue, inProvider := cfg[key]
+	providerSource := func() string {
+		if a, ok := provider.(SourceAnnotator); ok {
+			if src := a.Source(key); src != "" {
+				return src
+			}
+		}
+		return SourceProvider
+	}
+
+	if l.lookupChain != nil {
+		value, ok := envconfig.MultiLookuper(l.lookupChain(envconfig.MapLookuper(cfg))...).Lookup(key)
+		switch {
+		case !ok:
+			return ""
+		case inProvider && value == providerValue:
+			return providerSource()
+		}
+		return SourceLookupChain
+	}
+
+	_, inOS := os.LookupEnv(key)
+
+	switch {
+	case l.lookupOrder == LookupProviderOnly:
+		inOS = false
+	case l.lookupOrder == LookupProviderFirst && inProvider:
+		inOS = false
+	}
+
+	switch {
+	case inOS:
+		return SourceOS
+	case inProvider:
+		return providerSource()
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+// annotatedProvider is a provider that annotates every key with the same source.
+type annotatedProvider struct {
+	*MockProvider
+	source string
+}
+
+func (p annotatedProvider) Source(string) string {
+	return p.source
+}
+
+func TestExplainConfig(t *testing.T) {
+	type Config struct {
+		Name     string `env:"SALOME_EXPLAIN_NAME"`
+		Port     int    `env:"SALOME_EXPLAIN_PORT,default=8080"`
+		Password string `env:"SALOME_EXPLAIN_PASSWORD" secret:"true"`
+		Region   string `env:"SALOME_EXPLAIN_REGION"`
+		Unset    string `env:"SALOME_EXPLAIN_UNSET"`
+	}
+
+	t.Setenv("SALOME_EXPLAIN_REGION", "os-region")
+
+	cfgMap := map[string]string{
+		"SALOME_EXPLAIN_NAME":     "app",
+		"SALOME_EXPLAIN_PASSWORD": "hunter2",
+		"SALOME_EXPLAIN_REGION":   "provider-region",
+	}
+
+	t.Run("annotated provider", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+		providerMock.EXPECT().Config(gomock.Any()).Return(cfgMap, nil)
+
+		sources, err := ExplainConfig[Config](annotatedProvider{MockProvider: providerMock, source: "dotenv:.env"})
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		expected := []FieldSource{
+			{Key: "SALOME_EXPLAIN_NAME", Field: "Name", Value: "app", Source: "dotenv:.env"},
+			{Key: "SALOME_EXPLAIN_PORT", Field: "Port", Value: 8080, Source: SourceDefault, Default: true},
+			{Key: "SALOME_EXPLAIN_PASSWORD", Field: "Password", Value: "******", Source: "dotenv:.env"},
+			{Key: "SALOME_EXPLAIN_REGION", Field: "Region", Value: "os-region", Source: SourceOS},
+			{Key: "SALOME_EXPLAIN_UNSET", Field: "Unset", Value: ""},
+		}
+		if !reflect.DeepEqual(expected, sources) {
+			t.Errorf("expecting %v, got %v", expected, sources)
+		}
+	})
+
+	t.Run("provider first without annotations", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+		providerMock.EXPECT().Config(gomock.Any()).Return(cfgMap, nil)
+
+		sources, err := ExplainConfig[Config](providerMock, WithLookupOrder(LookupProviderFirst))
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		region := sources[3]
+		if region.Value != "provider-region" || region.Source != SourceProvider {
+			t.Errorf("expecting provider-region from %q, got %v from %q", SourceProvider, region.Value, region.Source)
+		}
+	})
+}
+
+func TestDynamicExplain(t *testing.T) {
+	type Config struct {
+		Name string `env:"SALOME_DYNAMIC_EXPLAIN_NAME"`
+	}
+
+	ctrl := gomock.NewController(t)
+	providerMock := NewMockProvider(ctrl)
+	providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{"SALOME_DYNAMIC_EXPLAIN_NAME": "app"}, nil)
+
+	d, err := NewDynamic(annotatedProvider{MockProvider: providerMock, source: "infisical:app/prod"})
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	key := &Config{}
+	if err := d.RegisterConfig(key, func() any { return &Config{} }); err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	sources, err := d.Explain(key)
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	expected := []FieldSource{
+		{Key: "SALOME_DYNAMIC_EXPLAIN_NAME", Field: "Name", Value: "app", Source: "infisical:app/prod"},
+	}
+	if !reflect.DeepEqual(expected, sources) {
+		t.Errorf("expecting %v, got %v", expected, sources)
+	}
+
+	if _, err := d.Explain("unknown"); err == nil {
+		t.Errorf("expecting error on unregistered key")
+	}
+}
@@ -0,0 +1,181 @@
+// Package featureflag provides feature flags defined in config values and kept up to date by
+// [config.Dynamic].
+//
+// Flags are fields of a config struct, like any other config:
+//
+//	type Flags struct {
+//		NewCheckout featureflag.Bool    `env:"FLAG_NEW_CHECKOUT"`
+//		Theme       featureflag.Variant `env:"FLAG_THEME"`
+//	}
+//
+//	client, err := featureflag.New[Flags](dynamic, featureflag.WithRecorder(recorder))
+//	...
+//	if client.Flags().NewCheckout.Enabled(ctx, featureflag.Subject{ID: tenantID}) { ... }
+//
+// See [Bool] and [Variant] for the syntax of the config values.
+package featureflag
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	config "github.com/raf555/salome/config/v1"
+	"github.com/raf555/salome/melt/metric"
+)
+
+// Client holds the latest flags of T. Reading them is lock-free.
+type Client[T any] struct {
+	flags    atomic.Pointer[T]
+	fields   []boundField
+	recorder metric.Recorder
+}
+
+// boundField is a flag field of T with its config key.
+type boundField struct {
+	key   string
+	index [][]int // index of each field along the path, pointers are followed in between
+}
+
+type binder interface {
+	bind(name string, recorder metric.Recorder)
+}
+
+var binderType = reflect.TypeFor[binder]()
+
+type options struct {
+	recorder metric.Recorder
+}
+
+type Option func(*options)
+
+// WithRecorder sets the recorder of flag evaluations (see [EvaluationMetric]).
+// Defaults to the recorder of the evaluation context (see [metric.FromContext]).
+func WithRecorder(recorder metric.Recorder) Option {
+	return func(o *options) {
+		o.recorder = recorder
+	}
+}
+
+// New registers the flags struct T to the manager and keeps the flags up to date on change.
+// Flags are named after their config key in metrics, and the key is the default salt of rollouts.
+// Flag fields can't be pointers, though they can be in structs behind pointers.
+func New[T any](mgr config.DynamicConfigManager, opts ...Option) (*Client[T], error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	fields, err := flagFields(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, fmt.Errorf("flagFields: %w", err)
+	}
+
+	getter, err := config.LoadDynamicConfigToWithNotify[T](mgr)
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadDynamicConfigToWithNotify: %w", err)
+	}
+
+	c := &Client[T]{
+		fields:   fields,
+		recorder: o.recorder,
+	}
+
+	getter.RegisterCallback(c.store)
+
+	// an update may have been stored by the callback already
+	initial := c.bind(getter.Get())
+	c.flags.CompareAndSwap(nil, initial)
+
+	return c, nil
+}
+
+// Flags returns the latest flags. They must not be modified.
+func (c *Client[T]) Flags() *T {
+	return c.flags.Load()
+}
+
+func (c *Client[T]) store(flags T) {
+	c.flags.Store(c.bind(flags))
+}
+
+// bind names the flags of a copy of flags.
+func (c *Client[T]) bind(flags T) *T {
+	root := reflect.ValueOf(&flags).Elem()
+
+	for _, f := range c.fields {
+		v, ok := fieldByIndexes(root, f.index)
+		if !ok {
+			continue
+		}
+		v.Addr().Interface().(binder).bind(f.key, c.recorder)
+	}
+
+	return &flags
+}
+
+func fieldByIndexes(v reflect.Value, indexes [][]int) (reflect.Value, bool) {
+	for i, index := range indexes {
+		if i > 0 {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByIndex(index)
+	}
+	return v, true
+}
+
+// flagFields finds the flags of t by their documented config fields.
+func flagFields(t reflect.Type) ([]boundField, error) {
+	docs, err := config.DescribeConfigType(t)
+	if err != nil {
+		return nil, fmt.Errorf("config.DescribeConfigType: %w", err)
+	}
+
+	var fields []boundField
+	for _, doc := range docs {
+		index, ft, ok := fieldIndexes(t, strings.Split(doc.Field, "."))
+		if !ok {
+			continue
+		}
+		if ft.Kind() == reflect.Pointer && ft.Implements(binderType) {
+			// binding through the pointer would modify the flag shared with the config of the manager
+			return nil, fmt.Errorf("%s: pointer flag field is not supported, use %s", doc.Field, ft.Elem())
+		}
+		if !reflect.PointerTo(ft).Implements(binderType) {
+			continue
+		}
+
+		fields = append(fields, boundField{key: doc.Key, index: index})
+	}
+
+	return fields, nil
+}
+
+// fieldIndexes resolves the dotted field path in t. Every index but the first one starts from
+// a pointer to a struct.
+func fieldIndexes(t reflect.Type, path []string) ([][]int, reflect.Type, bool) {
+	var indexes [][]int
+	var index []int
+
+	for _, name := range path {
+		if t.Kind() == reflect.Pointer {
+			indexes = append(indexes, index)
+			index = nil
+			t = t.Elem()
+		}
+
+		sf, ok := t.FieldByName(name)
+		if !ok {
+			return nil, nil, false
+		}
+
+		index = append(index, sf.Index...)
+		t = sf.Type
+	}
+
+	return append(indexes, index), t, true
+}
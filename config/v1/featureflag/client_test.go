@@ -0,0 +1,121 @@
+package featureflag
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	config "github.com/raf555/salome/config/v1"
+	"github.com/raf555/salome/melt/metric"
+)
+
+type mapProvider struct {
+	mu  sync.Mutex
+	cfg map[string]string
+}
+
+func (p *mapProvider) Config(context.Context) (map[string]string, error) {
+	return p.FetchConfig(context.Background())
+}
+
+func (p *mapProvider) FetchConfig(context.Context) (map[string]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.cfg), nil
+}
+
+func (p *mapProvider) set(key, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg[key] = value
+}
+
+type countingRecorder struct {
+	metric.NoopRecorder
+
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (r *countingRecorder) Count(_ context.Context, name string, value int64, opts ...metric.RecordOption) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[name] += value
+}
+
+func TestClient(t *testing.T) {
+	type Flags struct {
+		NewCheckout Bool `env:"FLAG_NEW_CHECKOUT"`
+		Theme       *struct {
+			Color Variant `env:"COLOR"`
+		} `env:",prefix=FLAG_THEME_"`
+	}
+
+	synctest.Test(t, func(t *testing.T) {
+		provider := &mapProvider{cfg: map[string]string{
+			"FLAG_NEW_CHECKOUT": "false",
+			"FLAG_THEME_COLOR":  `{"variants": [{"name": "blue", "weight": 1}]}`,
+		}}
+
+		dynamic, err := config.NewDynamic(provider,
+			config.WithAuditSink(config.AuditSinkFunc(func(context.Context, config.ChangeEvent) {})),
+			config.WithLoaderOptions(config.WithLookupOrder(config.LookupProviderOnly)),
+		)
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+		dynamic.Start()
+		defer dynamic.Close(t.Context())
+
+		recorder := &countingRecorder{counts: map[string]int64{}}
+		client, err := New[Flags](dynamic, WithRecorder(recorder))
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		s := Subject{ID: "tenant-1"}
+		if client.Flags().NewCheckout.Enabled(t.Context(), s) {
+			t.Errorf("expecting flag to be off")
+		}
+		if got := client.Flags().Theme.Color.Variant(t.Context(), s); got != "blue" {
+			t.Errorf("expecting blue, got %q", got)
+		}
+		if name := client.Flags().Theme.Color.f.name; name != "FLAG_THEME_COLOR" {
+			t.Errorf("expecting flag to be named after its key, got %q", name)
+		}
+
+		provider.set("FLAG_NEW_CHECKOUT", `{"allow": ["tenant-1"], "rollout": 0}`)
+		time.Sleep(15 * time.Second)
+
+		if !client.Flags().NewCheckout.Enabled(t.Context(), s) {
+			t.Errorf("expecting flag to be on after reload")
+		}
+
+		if got := recorder.counts[EvaluationMetric]; got != 3 {
+			t.Errorf("expecting 3 evaluations recorded, got %d", got)
+		}
+	})
+}
+
+func TestClientRejectsPointerFlags(t *testing.T) {
+	type Flags struct {
+		NewCheckout *Bool `env:"FLAG_NEW_CHECKOUT"`
+	}
+
+	provider := &mapProvider{cfg: map[string]string{"FLAG_NEW_CHECKOUT": "true"}}
+	dynamic, err := config.NewDynamic(provider,
+		config.WithAuditSink(config.AuditSinkFunc(func(context.Context, config.ChangeEvent) {})),
+		config.WithLoaderOptions(config.WithLookupOrder(config.LookupProviderOnly)),
+	)
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	if _, err := New[Flags](dynamic); err == nil || !strings.Contains(err.Error(), "NewCheckout: pointer flag field") {
+		t.Errorf("expecting pointer flag fields to be rejected, got %v", err)
+	}
+}
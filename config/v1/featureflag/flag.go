@@ -0,0 +1,320 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/raf555/salome/melt/metric"
+)
+
+// ErrInvalidFlag is returned when a flag can't be decoded from its config value.
+var ErrInvalidFlag = errors.New("featureflag: invalid flag")
+
+// Variants of [Bool] flags.
+const (
+	VariantOn  = "on"
+	VariantOff = "off"
+)
+
+// Reasons reported with evaluations.
+const (
+	ReasonDisabled = "disabled"
+	ReasonDeny     = "deny"
+	ReasonAllow    = "allow"
+	ReasonRule     = "rule"
+	ReasonRollout  = "rollout"
+	ReasonDefault  = "default"
+)
+
+// EvaluationMetric is the name of the counter of flag evaluations, labeled by flag, variant and reason.
+const EvaluationMetric = "featureflag_evaluation"
+
+// Subject is what a flag is evaluated for, e.g. a user or a tenant.
+type Subject struct {
+	// ID is matched against the allow and deny lists, and its hash decides the rollout bucket.
+	ID string
+	// Attributes are matched against the targeting rules, e.g. {"country": "ID"}.
+	Attributes map[string]string
+}
+
+// Rule serves a variant to subjects having one of the given values of an attribute.
+type Rule struct {
+	Attribute string   `json:"attribute"`
+	Values    []string `json:"values"`
+	Variant   string   `json:"variant"`
+}
+
+// WeightedVariant is a variant served to a share of the rollout proportional to its weight.
+type WeightedVariant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// spec is the definition of a flag, as written in the config value.
+type spec struct {
+	Enabled *bool `json:"enabled"`
+	// Rollout is the percentage of subjects, from 0 to 100, served by the variants.
+	Rollout  *float64          `json:"rollout"`
+	Allow    []string          `json:"allow"`
+	Deny     []string          `json:"deny"`
+	Rules    []Rule            `json:"rules"`
+	Variants []WeightedVariant `json:"variants"`
+	// Default is served to subjects out of the rollout, and when the flag is disabled.
+	Default string `json:"default"`
+	// Salt changes the rollout buckets. Defaults to the config key of the flag.
+	Salt string `json:"salt"`
+}
+
+// flag is the decoded, immutable state shared by [Bool] and [Variant].
+type flag struct {
+	enabled      bool
+	rollout      int // in basis points, i.e. 0-10000
+	allow        map[string]bool
+	deny         map[string]bool
+	rules        []Rule
+	variants     []WeightedVariant
+	totalWeight  int
+	defaultValue string
+	salt         string
+
+	name     string
+	recorder metric.Recorder
+}
+
+// decodeFlag parses value as a boolean ("true", "false", ...) or a JSON spec.
+// Variants default to the given ones, and the default variant to offVariant.
+func decodeFlag(value string, variants []WeightedVariant, offVariant string) (*flag, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return &flag{defaultValue: offVariant}, nil
+	}
+
+	var s spec
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		s.Enabled = &enabled
+	} else if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFlag, err)
+	}
+
+	f := &flag{
+		enabled:      s.Enabled == nil || *s.Enabled,
+		rollout:      10000,
+		allow:        set(s.Allow),
+		deny:         set(s.Deny),
+		rules:        s.Rules,
+		variants:     variants,
+		defaultValue: offVariant,
+		salt:         s.Salt,
+	}
+
+	if s.Rollout != nil {
+		if *s.Rollout < 0 || *s.Rollout > 100 {
+			return nil, fmt.Errorf("%w: rollout %v is not between 0 and 100", ErrInvalidFlag, *s.Rollout)
+		}
+		f.rollout = int(math.Round(*s.Rollout * 100))
+	}
+
+	if len(s.Variants) > 0 {
+		f.variants = s.Variants
+	}
+	if len(f.variants) == 0 {
+		return nil, fmt.Errorf("%w: no variants", ErrInvalidFlag)
+	}
+
+	for _, v := range f.variants {
+		if v.Weight < 0 {
+			return nil, fmt.Errorf("%w: variant %q has a negative weight", ErrInvalidFlag, v.Name)
+		}
+		f.totalWeight += v.Weight
+	}
+	if f.totalWeight == 0 {
+		return nil, fmt.Errorf("%w: variants have no weight", ErrInvalidFlag)
+	}
+
+	if s.Default != "" {
+		f.defaultValue = s.Default
+	}
+	if f.defaultValue == "" {
+		f.defaultValue = f.variants[0].Name
+	}
+
+	known := func(variant string) bool {
+		return (offVariant != "" && variant == offVariant) || slices.ContainsFunc(f.variants, func(v WeightedVariant) bool {
+			return v.Name == variant
+		})
+	}
+
+	if !known(f.defaultValue) {
+		return nil, fmt.Errorf("%w: unknown default variant %q", ErrInvalidFlag, f.defaultValue)
+	}
+	for _, r := range f.rules {
+		if r.Attribute == "" || !known(r.Variant) {
+			return nil, fmt.Errorf("%w: rule on attribute %q serves unknown variant %q", ErrInvalidFlag, r.Attribute, r.Variant)
+		}
+	}
+
+	return f, nil
+}
+
+func set(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
+
+// evaluate returns the variant served to the subject and the reason.
+func (f *flag) evaluate(s Subject) (string, string) {
+	switch {
+	case !f.enabled:
+		return f.defaultValue, ReasonDisabled
+	case f.deny[s.ID]:
+		return f.defaultValue, ReasonDeny
+	case f.allow[s.ID]:
+		return f.pick(s.ID), ReasonAllow
+	}
+
+	for _, r := range f.rules {
+		if value, ok := s.Attributes[r.Attribute]; ok && slices.Contains(r.Values, value) {
+			return r.Variant, ReasonRule
+		}
+	}
+
+	if bucket(f.salt, "rollout", s.ID, 10000) < f.rollout {
+		return f.pick(s.ID), ReasonRollout
+	}
+	return f.defaultValue, ReasonDefault
+}
+
+// pick chooses a variant by weight. It is bucketed independently of the rollout,
+// so growing the rollout doesn't move subjects between variants.
+func (f *flag) pick(id string) string {
+	n := bucket(f.salt, "variant", id, f.totalWeight)
+	for _, v := range f.variants {
+		if n < v.Weight {
+			return v.Name
+		}
+		n -= v.Weight
+	}
+	return f.variants[len(f.variants)-1].Name
+}
+
+// bucket hashes the subject ID into [0, n).
+func bucket(salt, purpose, id string, n int) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(salt + "/" + purpose + "/" + id))
+	return int(h.Sum64() % uint64(n))
+}
+
+func (f *flag) record(ctx context.Context, variant, reason string) {
+	recorder := f.recorder
+	if recorder == nil {
+		recorder = metric.FromContext(ctx)
+	}
+
+	recorder.Count(ctx, EvaluationMetric, 1, metric.WithLabel(metric.LabelMap{
+		"flag":    f.name,
+		"variant": variant,
+		"reason":  reason,
+	}))
+}
+
+// bound returns a copy of the flag with the given name and recorder of its evaluations.
+// The salt defaults to the name.
+func (f *flag) bound(name string, recorder metric.Recorder) *flag {
+	if f == nil {
+		return nil
+	}
+
+	out := *f
+	out.name = name
+	out.recorder = recorder
+	if out.salt == "" {
+		out.salt = name
+	}
+	return &out
+}
+
+// Bool is an on/off flag decoded from a config value, either a boolean or a JSON spec:
+//
+//	{"enabled": true, "rollout": 25, "allow": ["tenant-1"], "deny": ["tenant-2"],
+//	 "rules": [{"attribute": "country", "values": ["ID"], "variant": "on"}]}
+//
+// Rules serve [VariantOn] or [VariantOff]. An unset flag is off.
+type Bool struct {
+	f *flag
+}
+
+// EnvDecode implements envconfig.Decoder.
+func (b *Bool) EnvDecode(value string) error {
+	f, err := decodeFlag(value, []WeightedVariant{{Name: VariantOn, Weight: 1}}, VariantOff)
+	if err != nil {
+		return err
+	}
+
+	b.f = f
+	return nil
+}
+
+// Enabled reports whether the flag is on for the subject.
+func (b Bool) Enabled(ctx context.Context, s Subject) bool {
+	if b.f == nil {
+		return false
+	}
+
+	variant, reason := b.f.evaluate(s)
+	b.f.record(ctx, variant, reason)
+	return variant == VariantOn
+}
+
+func (b *Bool) bind(name string, recorder metric.Recorder) {
+	b.f = b.f.bound(name, recorder)
+}
+
+// Variant is a multivariate flag decoded from a JSON spec with weighted variants:
+//
+//	{"rollout": 50, "variants": [{"name": "blue", "weight": 1}, {"name": "green", "weight": 1}], "default": "control"}
+//
+// Subjects out of the rollout, or evaluating a disabled flag, are served the default variant,
+// which defaults to the first variant. An unset flag serves "".
+type Variant struct {
+	f *flag
+}
+
+// EnvDecode implements envconfig.Decoder.
+func (v *Variant) EnvDecode(value string) error {
+	f, err := decodeFlag(value, nil, "")
+	if err != nil {
+		return err
+	}
+
+	v.f = f
+	return nil
+}
+
+// Variant returns the variant served to the subject.
+func (v Variant) Variant(ctx context.Context, s Subject) string {
+	if v.f == nil {
+		return ""
+	}
+
+	variant, reason := v.f.evaluate(s)
+	v.f.record(ctx, variant, reason)
+	return variant
+}
+
+func (v *Variant) bind(name string, recorder metric.Recorder) {
+	v.f = v.f.bound(name, recorder)
+}
@@ -0,0 +1,144 @@
+package featureflag
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBool(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		subject  Subject
+		expected bool
+	}{
+		{name: "unset", value: "", expected: false},
+		{name: "true", value: "true", subject: Subject{ID: "a"}, expected: true},
+		{name: "false", value: "false", subject: Subject{ID: "a"}, expected: false},
+		{name: "disabled spec", value: `{"enabled": false, "allow": ["a"]}`, subject: Subject{ID: "a"}, expected: false},
+		{name: "allow list", value: `{"rollout": 0, "allow": ["a"]}`, subject: Subject{ID: "a"}, expected: true},
+		{name: "deny list", value: `{"deny": ["a"]}`, subject: Subject{ID: "a"}, expected: false},
+		{
+			name:     "rule",
+			value:    `{"rollout": 0, "rules": [{"attribute": "country", "values": ["ID", "SG"], "variant": "on"}]}`,
+			subject:  Subject{ID: "a", Attributes: map[string]string{"country": "SG"}},
+			expected: true,
+		},
+		{
+			name:     "rule not matching",
+			value:    `{"rollout": 0, "rules": [{"attribute": "country", "values": ["ID"], "variant": "on"}]}`,
+			subject:  Subject{ID: "a", Attributes: map[string]string{"country": "SG"}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b Bool
+			if err := b.EnvDecode(tt.value); err != nil {
+				t.Fatalf("expecting nil error, got %v", err)
+			}
+
+			if got := b.Enabled(t.Context(), tt.subject); got != tt.expected {
+				t.Errorf("expecting %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBoolRollout(t *testing.T) {
+	var b Bool
+	if err := b.EnvDecode(`{"rollout": 25, "salt": "checkout"}`); err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	enabled := 0
+	for i := range 10000 {
+		s := Subject{ID: fmt.Sprintf("user-%d", i)}
+		got := b.Enabled(t.Context(), s)
+		if got != b.Enabled(t.Context(), s) {
+			t.Fatalf("expecting stable evaluation for %q", s.ID)
+		}
+		if got {
+			enabled++
+		}
+	}
+
+	if enabled < 2300 || enabled > 2700 {
+		t.Errorf("expecting about 25%% enabled, got %d out of 10000", enabled)
+	}
+}
+
+func TestRolloutBasisPoints(t *testing.T) {
+	// 0.29 * 100 is 28.999999999999996 in floating point
+	for rollout, expected := range map[string]int{"0.29": 29, "0.57": 57, "29": 2900, "100": 10000} {
+		f, err := decodeFlag(`{"rollout": `+rollout+`}`, []WeightedVariant{{Name: VariantOn, Weight: 1}}, VariantOff)
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+		if f.rollout != expected {
+			t.Errorf("expecting rollout %s to be %d basis points, got %d", rollout, expected, f.rollout)
+		}
+	}
+}
+
+func TestVariant(t *testing.T) {
+	var v Variant
+	err := v.EnvDecode(`{
+		"rollout": 50,
+		"variants": [{"name": "blue", "weight": 1}, {"name": "green", "weight": 3}],
+		"default": "control",
+		"rules": [{"attribute": "plan", "values": ["beta"], "variant": "green"}]
+	}`)
+	if err == nil {
+		t.Fatalf("expecting error on unknown default variant")
+	}
+
+	err = v.EnvDecode(`{
+		"rollout": 50,
+		"variants": [{"name": "control", "weight": 0}, {"name": "blue", "weight": 1}, {"name": "green", "weight": 3}],
+		"default": "control",
+		"rules": [{"attribute": "plan", "values": ["beta"], "variant": "green"}]
+	}`)
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	if got := v.Variant(t.Context(), Subject{ID: "a", Attributes: map[string]string{"plan": "beta"}}); got != "green" {
+		t.Errorf("expecting rule variant green, got %q", got)
+	}
+
+	counts := map[string]int{}
+	for i := range 10000 {
+		counts[v.Variant(t.Context(), Subject{ID: fmt.Sprintf("user-%d", i)})]++
+	}
+
+	if counts["control"] < 4700 || counts["control"] > 5300 {
+		t.Errorf("expecting about half out of the rollout, got %v", counts)
+	}
+	if counts["green"] < 2*counts["blue"] {
+		t.Errorf("expecting green to be served about 3 times as blue, got %v", counts)
+	}
+}
+
+func TestDecodeInvalidFlag(t *testing.T) {
+	values := []string{
+		`not json`,
+		`{"rollout": 101}`,
+		`{"rules": [{"attribute": "country", "values": ["ID"], "variant": "maybe"}]}`,
+		`{"variants": [{"name": "a", "weight": -1}]}`,
+	}
+
+	for _, value := range values {
+		var b Bool
+		if err := b.EnvDecode(value); !errors.Is(err, ErrInvalidFlag) {
+			t.Errorf("expecting ErrInvalidFlag for %q, got %v", value, err)
+		}
+	}
+
+	var v Variant
+	if err := v.EnvDecode(`{"rollout": 10}`); !errors.Is(err, ErrInvalidFlag) {
+		t.Errorf("expecting ErrInvalidFlag for a variant flag without variants, got %v", err)
+	}
+}
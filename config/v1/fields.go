@@ -0,0 +1,172 @@
+package config
+
+import (
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/raf555/salome/config/v1/internal/envtag"
+	"github.com/sethvargo/go-envconfig"
+)
+
+// configField is a leaf field of a config struct, resolved the same way envconfig resolves it.
+type configField struct {
+	// Name is the dotted Go path of the field, e.g. "Database.Host".
+	Name string
+	// Key is the fully resolved key, including the prefixes of parent structs.
+	Key      string
+	Tag      envtag.Tag
+	Required bool
+	// Secret is set by the `secret:"true"` tag on the field or any of its parent structs.
+	Secret bool
+	Field  reflect.StructField
+	// Index is the index sequence for reflect.Value.FieldByIndex, pointers are dereferenced in between.
+	Index []int
+}
+
+var (
+	decoderCtxType      = reflect.TypeFor[envconfig.DecoderCtx]()
+	decoderType         = reflect.TypeFor[envconfig.Decoder]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	binUnmarshalerType  = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+	gobDecoderType      = reflect.TypeFor[gob.GobDecoder]()
+)
+
+// findConfigField finds the field by its dotted Go path.
+func findConfigField(fields []configField, name string) (configField, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return configField{}, false
+}
+
+// configFields lists the leaf fields of the struct type t (or pointer to it).
+// isLeaf may be given to stop at struct types that are decoded as a whole by other means.
+func configFields(t reflect.Type, isLeaf func(reflect.Type) bool) ([]configField, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: %w", t, envconfig.ErrNotStruct)
+	}
+
+	w := fieldWalker{
+		isLeaf:   isLeaf,
+		visiting: map[reflect.Type]bool{},
+	}
+	if err := w.walk(t, walkScope{}); err != nil {
+		return nil, err
+	}
+	return w.out, nil
+}
+
+type fieldWalker struct {
+	isLeaf   func(reflect.Type) bool
+	visiting map[reflect.Type]bool
+	out      []configField
+}
+
+// walkScope holds what a nested struct inherits from its parents.
+type walkScope struct {
+	namePrefix string
+	keyPrefix  string
+	index      []int
+	required   bool
+	secret     bool
+}
+
+func (w *fieldWalker) walk(t reflect.Type, scope walkScope) error {
+	if w.visiting[t] {
+		return fmt.Errorf("%s: recursive config struct is not supported", t)
+	}
+	w.visiting[t] = true
+	defer delete(w.visiting, t)
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		rawTag := sf.Tag.Get("env")
+		tag, err := envtag.Parse(rawTag)
+		if err != nil {
+			return fmt.Errorf("%s%s: %w", scope.namePrefix, sf.Name, err)
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		secret, _ := strconv.ParseBool(sf.Tag.Get("secret"))
+
+		name := scope.namePrefix + sf.Name
+		fieldScope := walkScope{
+			namePrefix: name + ".",
+			keyPrefix:  scope.keyPrefix + tag.Prefix,
+			index:      append(append([]int(nil), scope.index...), i),
+			required:   scope.required || tag.Required,
+			secret:     scope.secret || secret,
+		}
+
+		leaf := tag.Key != "" && (isDecoder(ft) || (w.isLeaf != nil && w.isLeaf(sf.Type)))
+		if ft.Kind() == reflect.Struct && !leaf {
+			if err := w.walk(ft, fieldScope); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if rawTag == "" || tag.Key == "" {
+			continue
+		}
+
+		w.out = append(w.out, configField{
+			Name:     name,
+			Key:      scope.keyPrefix + tag.Key,
+			Tag:      tag,
+			Required: fieldScope.required,
+			Secret:   fieldScope.secret,
+			Field:    sf,
+			Index:    fieldScope.index,
+		})
+	}
+
+	return nil
+}
+
+// isDecoder reports whether envconfig decodes t as a whole instead of walking its fields.
+func isDecoder(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	for _, iface := range []reflect.Type{
+		decoderCtxType, decoderType, textUnmarshalerType,
+		jsonUnmarshalerType, binUnmarshalerType, gobDecoderType,
+	} {
+		if pt.Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the field within the struct v, following pointers.
+// It returns false if a pointer on the way is nil.
+func (f configField) value(v reflect.Value) (reflect.Value, bool) {
+	for _, i := range f.Index {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
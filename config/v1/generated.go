@@ -0,0 +1,384 @@
+package config
+
+import (
+	"context"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raf555/salome/config/v1/internal/fingerprint"
+	"github.com/sethvargo/go-envconfig"
+)
+
+// GeneratedConfig is implemented by config structs with methods generated by cmd/configgen.
+// The loader uses DecodeConfig instead of decoding the struct by reflection, unless custom decoders
+// are registered with [WithDecoder]. [Dynamic] uses EqualConfig instead of [reflect.DeepEqual]
+// to detect changes, comparing only the fields decoded from the config. Validation is unchanged.
+//
+// The methods are meant to be generated, and they must be kept in sync with the struct with go:generate:
+//
+//	//go:generate go run github.com/raf555/salome/config/v1/cmd/configgen -type Config
+//
+// ConfigFingerprint is a hash of the fields, their types and `env` tags of the struct when the methods were generated.
+// If the struct has changed since, the generated methods are stale: they're ignored and the struct is
+// decoded and compared by reflection until they're generated again.
+type GeneratedConfig interface {
+	DecodeConfig(d *FieldDecoder)
+	EqualConfig(other any) bool
+	ConfigFingerprint() string
+}
+
+// fingerprints caches the fingerprint of the structs of generated configs, by type.
+var fingerprints sync.Map
+
+// upToDate reports whether the generated methods of gen match its struct, see [GeneratedConfig].
+func upToDate(gen GeneratedConfig) bool {
+	t := reflect.TypeOf(gen)
+	if fp, ok := fingerprints.Load(t); ok {
+		return fp.(string) == gen.ConfigFingerprint()
+	}
+
+	fp, err := structFingerprint(t)
+	if err != nil {
+		// the error is reported by the reflection fallback
+		return false
+	}
+
+	fingerprints.Store(t, fp)
+	return fp == gen.ConfigFingerprint()
+}
+
+// structFingerprint returns the fingerprint of the config struct t (or pointer to it), as cmd/configgen does.
+func structFingerprint(t reflect.Type) (string, error) {
+	fields, err := configFields(t, nil)
+	if err != nil {
+		return "", fmt.Errorf("configFields: %w", err)
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fps := make([]fingerprint.Field, len(fields))
+	for i, f := range fields {
+		ft := f.Field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		fps[i] = fingerprint.Field{Name: f.Name, Key: f.Key, Type: f.Field.Type.String(), Decoder: isDecoder(ft)}
+
+		st := t
+		for _, idx := range f.Index {
+			sf := st.Field(idx)
+			fps[i].Tags = append(fps[i].Tags, fingerprint.Tag(sf.Tag.Get("env"), sf.Tag.Get("secret")))
+
+			st = sf.Type
+			for st.Kind() == reflect.Pointer {
+				st = st.Elem()
+			}
+		}
+	}
+
+	return fingerprint.Sum(fps), nil
+}
+
+// FieldSpec describes a config field to [FieldDecoder.Decode], as resolved by cmd/configgen.
+type FieldSpec struct {
+	// Name is the dotted Go path of the field, e.g. "Database.Host".
+	Name string
+	// Key is the fully resolved key, including the prefixes of parent structs.
+	Key string
+	// TagKey is the key of the `env` tag, as given to mutators.
+	TagKey    string
+	Default   string
+	Delimiter string
+	Required  bool
+	Secret    bool
+	Overwrite bool
+	NoInit    bool
+	// Lazy skips decoding when the key is unset and has no default, as envconfig does for struct decoders.
+	Lazy bool
+}
+
+// FieldDecoder decodes the fields of a [GeneratedConfig] the same way envconfig does, recording the
+// fields that fail to decode.
+type FieldDecoder struct {
+	ctx      context.Context
+	l        *loader
+	lookuper envconfig.Lookuper
+	failed   map[string]bool
+	verr     *ValidationError
+}
+
+// Context returns the context of the load.
+func (d *FieldDecoder) Context() context.Context {
+	return d.ctx
+}
+
+// Delimiter returns the delimiter of slice items: the one of the field, if any, or the one of the loader.
+func (d *FieldDecoder) Delimiter(f FieldSpec) string {
+	if f.Delimiter != "" {
+		return f.Delimiter
+	}
+	if d.l.delimiter != "" {
+		return d.l.delimiter
+	}
+	return ","
+}
+
+// Decode looks up the value of the field and passes it to decode. set reports whether the field
+// already has a non-zero value, which is kept unless the field is overwritten.
+func (d *FieldDecoder) Decode(f FieldSpec, set bool, decode func(value string) error) {
+	if set && !f.Overwrite {
+		return
+	}
+
+	value, found := d.lookuper.Lookup(f.Key)
+	usedDefault := false
+	if !found {
+		if f.Required {
+			d.fail(f, "", ruleRequired, "missing required value", fmt.Errorf("%w: %s", envconfig.ErrMissingRequired, f.Key))
+			return
+		}
+
+		if f.Default != "" {
+			value, usedDefault = d.expandDefault(f.Default), true
+		}
+	}
+
+	if set && !found {
+		return
+	}
+	if f.Lazy && !found && !usedDefault {
+		return
+	}
+
+	if found || usedDefault {
+		original := value
+		for _, mu := range d.l.mutators {
+			var (
+				stop bool
+				err  error
+			)
+			value, stop, err = mu.EnvMutate(d.ctx, f.TagKey, f.Key, original, value)
+			if err != nil {
+				d.fail(f, original, ruleDecode, err.Error(), fmt.Errorf("%s: %w", f.Name, err))
+				return
+			}
+			if stop {
+				break
+			}
+		}
+	}
+
+	if value == "" && f.NoInit {
+		return
+	}
+
+	if err := decode(value); err != nil {
+		d.fail(f, value, ruleDecode, err.Error(), fmt.Errorf("%s: %w", f.Name, err))
+	}
+}
+
+// expandDefault expands the references to other keys in a default value, handling the escaped "\\" and "\$"
+// the same way envconfig does. Keys that failed to decode expand to "", as they do on the reflective path.
+func (d *FieldDecoder) expandDefault(value string) string {
+	value = strings.ReplaceAll(value, "\\\\", "\u0000")
+	value = strings.ReplaceAll(value, "\\$", "\u0008")
+
+	value = os.Expand(value, func(key string) string {
+		if d.failed[key] {
+			return ""
+		}
+		v, _ := d.lookuper.Lookup(key)
+		return v
+	})
+
+	value = strings.ReplaceAll(value, "\u0000", "\\")
+	return strings.ReplaceAll(value, "\u0008", "$")
+}
+
+func (d *FieldDecoder) fail(f FieldSpec, value, rule, msg string, err error) {
+	if rule == ruleDecode {
+		msg, err = redactDecodeError(f.Secret, f.Name, msg, err)
+	}
+
+	d.failed[f.Key] = true
+	d.verr.Fields = append(d.verr.Fields, FieldError{
+		Field:   f.Name,
+		Key:     f.Key,
+		Value:   redactValue(configField{Secret: f.Secret}, value),
+		Rule:    rule,
+		Message: msg,
+		Err:     err,
+	})
+}
+
+// ParseString sets dst to a non-empty value.
+func ParseString[T ~string](value string, dst *T) error {
+	if value != "" {
+		*dst = T(value)
+	}
+	return nil
+}
+
+// ParseBool parses a non-empty value to dst with [strconv.ParseBool].
+func ParseBool[T ~bool](value string, dst *T) error {
+	if value == "" {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*dst = T(b)
+	return nil
+}
+
+// ParseInt parses a non-empty value to dst with [strconv.ParseInt], accepting base prefixes.
+func ParseInt[T ~int | ~int8 | ~int16 | ~int32 | ~int64](value string, bitSize int, dst *T) error {
+	if value == "" {
+		return nil
+	}
+
+	i, err := strconv.ParseInt(value, 0, bitSize)
+	if err != nil {
+		return err
+	}
+	*dst = T(i)
+	return nil
+}
+
+// ParseUint parses a non-empty value to dst with [strconv.ParseUint], accepting base prefixes.
+func ParseUint[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](value string, bitSize int, dst *T) error {
+	if value == "" {
+		return nil
+	}
+
+	i, err := strconv.ParseUint(value, 0, bitSize)
+	if err != nil {
+		return err
+	}
+	*dst = T(i)
+	return nil
+}
+
+// ParseFloat parses a non-empty value to dst with [strconv.ParseFloat].
+func ParseFloat[T ~float32 | ~float64](value string, bitSize int, dst *T) error {
+	if value == "" {
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(value, bitSize)
+	if err != nil {
+		return err
+	}
+	*dst = T(f)
+	return nil
+}
+
+// ParseDuration parses a non-empty value to dst with [time.ParseDuration].
+func ParseDuration(value string, dst *time.Duration) error {
+	if value == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*dst = d
+	return nil
+}
+
+// ParseBytes sets dst to a non-empty value.
+func ParseBytes(value string, dst *[]byte) error {
+	if value != "" {
+		*dst = []byte(value)
+	}
+	return nil
+}
+
+// ParseSlice splits a non-empty value by delimiter and parses every trimmed item to dst with parse.
+func ParseSlice[T any](value, delimiter string, dst *[]T, parse func(value string, dst *T) error) error {
+	if value == "" {
+		return nil
+	}
+
+	items := strings.Split(value, delimiter)
+	s := make([]T, len(items))
+	for i, item := range items {
+		item = strings.TrimSpace(item)
+		if err := parse(item, &s[i]); err != nil {
+			return fmt.Errorf("%s: %w", item, err)
+		}
+	}
+	*dst = s
+	return nil
+}
+
+// DecodeValue decodes value to dst, which must implement one of the decoder interfaces of envconfig.
+// They are tried in the same order as envconfig does.
+func DecodeValue(ctx context.Context, value string, dst any) error {
+	switch dec := dst.(type) {
+	case envconfig.DecoderCtx:
+		return dec.EnvDecode(ctx, value)
+	case envconfig.Decoder:
+		return dec.EnvDecode(value)
+	}
+
+	implemented := false
+	var err error
+	if tu, ok := dst.(encoding.TextUnmarshaler); ok {
+		implemented = true
+		if err = tu.UnmarshalText([]byte(value)); err == nil {
+			return nil
+		}
+	}
+	if ju, ok := dst.(json.Unmarshaler); ok {
+		implemented = true
+		if err = ju.UnmarshalJSON([]byte(value)); err == nil {
+			return nil
+		}
+	}
+	if bu, ok := dst.(encoding.BinaryUnmarshaler); ok {
+		implemented = true
+		if err = bu.UnmarshalBinary([]byte(value)); err == nil {
+			return nil
+		}
+	}
+	if gd, ok := dst.(gob.GobDecoder); ok {
+		implemented = true
+		if err = gd.GobDecode([]byte(value)); err == nil {
+			return nil
+		}
+	}
+
+	if !implemented {
+		return fmt.Errorf("%T doesn't implement a decoder", dst)
+	}
+	return err
+}
+
+// IsZero reports whether v is the zero value of its type, for the fields of a [GeneratedConfig]
+// that can't be compared with ==.
+func IsZero[T any](v T) bool {
+	return reflect.ValueOf(&v).Elem().IsZero()
+}
+
+// Equal reports whether the configs a and b are equal, using the generated EqualConfig if any and up to date,
+// and [reflect.DeepEqual] otherwise. It's how [Dynamic] decides whether a config changed.
+func Equal(a, b any) bool {
+	if g, ok := a.(GeneratedConfig); ok && upToDate(g) {
+		return g.EqualConfig(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
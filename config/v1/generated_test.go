@@ -0,0 +1,28 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStructFingerprintCoversTypes(t *testing.T) {
+	type narrow struct {
+		Port int32 `env:"PORT"`
+	}
+	type wide struct {
+		Port int64 `env:"PORT"`
+	}
+
+	a, err := structFingerprint(reflect.TypeFor[narrow]())
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+	b, err := structFingerprint(reflect.TypeFor[wide]())
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	if a == b {
+		t.Errorf("expecting structs differing only by a field type to have different fingerprints")
+	}
+}
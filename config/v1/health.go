@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrStale is returned by the readiness check of [Dynamic] when the config hasn't been fetched
+// successfully for too long.
+var ErrStale = errors.New("config: stale")
+
+// Health reports how fresh the config of a [Dynamic] is.
+type Health struct {
+	// LastSuccess is the time of the last successful fetch, or of the initial one.
+	LastSuccess time.Time `json:"lastSuccess"`
+	// ConsecutiveFailures is the number of failed fetches since LastSuccess.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// LastError describes the last failed fetch since LastSuccess, if any. The message of the provider error
+	// is left out, as it may contain config values.
+	LastError string `json:"lastError,omitempty"`
+	// Staleness is the time since LastSuccess.
+	Staleness time.Duration `json:"staleness"`
+	// StaleIntervals is Staleness relative to the fetch interval. It stays around 1 while fetches succeed.
+	StaleIntervals float64 `json:"staleIntervals"`
+}
+
+// Health returns how fresh the config is.
+func (d *Dynamic) Health() Health {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	staleness := time.Since(d.lastFetch)
+	h := Health{
+		LastSuccess:         d.lastFetch,
+		ConsecutiveFailures: d.fetchFailures,
+		Staleness:           staleness,
+		StaleIntervals:      float64(staleness) / float64(d.cfg.FetchInterval),
+	}
+	if d.lastFetchErr != nil {
+		h.LastError = statusError("fetch", d.lastFetchErr)
+	}
+	return h
+}
+
+// ReadinessCheck returns a check failing with [ErrStale] when the config hasn't been fetched successfully
+// for more than maxStaleness. It should be a few fetch intervals, so a single failed fetch is tolerated.
+func (d *Dynamic) ReadinessCheck(maxStaleness time.Duration) func(ctx context.Context) error {
+	return func(_ context.Context) error {
+		h := d.Health()
+		if h.Staleness <= maxStaleness {
+			return nil
+		}
+
+		err := fmt.Errorf("%w: last fetched %s ago, %d consecutive failure(s)",
+			ErrStale, h.Staleness.Round(time.Second), h.ConsecutiveFailures)
+		if h.LastError != "" {
+			err = fmt.Errorf("%w: %s", err, h.LastError)
+		}
+		return err
+	}
+}
+
+// ReadinessHandler adapts [Dynamic.ReadinessCheck] to a probe endpoint, e.g. for Kubernetes readiness probes.
+// It responds with the [Health] as JSON, with 503 Service Unavailable when the config is stale.
+func (d *Dynamic) ReadinessHandler(maxStaleness time.Duration) http.Handler {
+	check := d.ReadinessCheck(maxStaleness)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := http.StatusOK
+		if err := check(r.Context()); err != nil {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(d.Health())
+	})
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestDynamicHealth(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+
+		cfg := map[string]string{"SALOME_HEALTH_USER": "a"}
+		providerMock.EXPECT().Config(gomock.Any()).Return(cfg, nil)
+		gomock.InOrder(
+			providerMock.EXPECT().FetchConfig(gomock.Any()).Return(cfg, nil),
+			providerMock.EXPECT().FetchConfig(gomock.Any()).Return(nil, errors.New("unavailable: bad SALOME_HEALTH_USER=hunter2")).Times(3),
+			providerMock.EXPECT().FetchConfig(gomock.Any()).Return(cfg, nil).AnyTimes(),
+		)
+
+		dynamic, err := NewDynamic(providerMock, WithErrCallback(func(error) {}))
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+
+		dynamic.Start()
+		defer dynamic.Close(t.Context())
+
+		ready := dynamic.ReadinessCheck(25 * time.Second)
+		handler := dynamic.ReadinessHandler(25 * time.Second)
+		probe := func() (int, Health) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if strings.Contains(rec.Body.String(), "hunter2") {
+				t.Errorf("expecting the provider error to be redacted, got %s", rec.Body.String())
+			}
+
+			var h Health
+			if err := json.Unmarshal(rec.Body.Bytes(), &h); err != nil {
+				t.Fatalf("expecting a JSON health, got %v", err)
+			}
+			return rec.Code, h
+		}
+
+		// fetched successfully at 10s
+		time.Sleep(15 * time.Second)
+		synctest.Wait()
+		if h := dynamic.Health(); h.ConsecutiveFailures != 0 || h.Staleness != 5*time.Second || h.StaleIntervals != 0.5 {
+			t.Errorf("expecting a fresh config, got %+v", h)
+		}
+
+		// failed at 20s, 30s and 40s
+		time.Sleep(30 * time.Second)
+		synctest.Wait()
+		h := dynamic.Health()
+		if h.ConsecutiveFailures != 3 || h.LastError != "fetch failed" || h.Staleness != 35*time.Second {
+			t.Errorf("expecting 3 consecutive failures, got %+v", h)
+		}
+		if err := ready(t.Context()); !errors.Is(err, ErrStale) || strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("expecting a redacted ErrStale, got %v", err)
+		}
+		if code, h := probe(); code != http.StatusServiceUnavailable || h.ConsecutiveFailures != 3 {
+			t.Errorf("expecting 503 with 3 consecutive failures, got %d %+v", code, h)
+		}
+
+		// recovered at 50s
+		time.Sleep(10 * time.Second)
+		synctest.Wait()
+		if h := dynamic.Health(); h.ConsecutiveFailures != 0 || h.LastError != "" {
+			t.Errorf("expecting a recovered config, got %+v", h)
+		}
+		if err := ready(context.Background()); err != nil {
+			t.Errorf("expecting nil error, got %v", err)
+		}
+		if code, _ := probe(); code != http.StatusOK {
+			t.Errorf("expecting 200, got %d", code)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+// Code generated by configgen -type Config. DO NOT EDIT.
+
+package configgentest
+
+import (
+	"reflect"
+	"slices"
+
+	config "github.com/raf555/salome/config/v1"
+)
+
+// DecodeConfig implements [config.GeneratedConfig].
+func (c *Config) DecodeConfig(d *config.FieldDecoder) {
+	{
+		spec := config.FieldSpec{Name: "Host", Key: "HOST", TagKey: "HOST", Required: true}
+		d.Decode(spec, c.Host != "", func(v string) error {
+			return config.ParseString(v, &c.Host)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Port", Key: "PORT", TagKey: "PORT", Default: "8080"}
+		d.Decode(spec, c.Port != 0, func(v string) error {
+			return config.ParseInt(v, 0, &c.Port)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Debug", Key: "DEBUG", TagKey: "DEBUG"}
+		d.Decode(spec, c.Debug, func(v string) error {
+			return config.ParseBool(v, &c.Debug)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Timeout", Key: "TIMEOUT", TagKey: "TIMEOUT", Default: "5s"}
+		d.Decode(spec, c.Timeout != 0, func(v string) error {
+			return config.ParseDuration(v, &c.Timeout)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Ratio", Key: "RATIO", TagKey: "RATIO", Default: "0.5"}
+		d.Decode(spec, c.Ratio != 0, func(v string) error {
+			return config.ParseFloat(v, 64, &c.Ratio)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "MaxConns", Key: "MAX_CONNS", TagKey: "MAX_CONNS", Default: "0x10"}
+		d.Decode(spec, c.MaxConns != 0, func(v string) error {
+			return config.ParseUint(v, 16, &c.MaxConns)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Hosts", Key: "HOSTS", TagKey: "HOSTS", Delimiter: ";"}
+		d.Decode(spec, c.Hosts != nil, func(v string) error {
+			return config.ParseSlice(v, d.Delimiter(spec), &c.Hosts, func(v string, dst *string) error {
+				return config.ParseString(v, dst)
+			})
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Ports", Key: "PORTS", TagKey: "PORTS"}
+		d.Decode(spec, c.Ports != nil, func(v string) error {
+			return config.ParseSlice(v, d.Delimiter(spec), &c.Ports, func(v string, dst *int) error {
+				return config.ParseInt(v, 0, dst)
+			})
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Token", Key: "TOKEN", TagKey: "TOKEN", Secret: true}
+		d.Decode(spec, c.Token != nil, func(v string) error {
+			return config.ParseBytes(v, &c.Token)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Pin", Key: "PIN", TagKey: "PIN", Secret: true}
+		d.Decode(spec, c.Pin != 0, func(v string) error {
+			return config.ParseInt(v, 0, &c.Pin)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Level", Key: "LEVEL", TagKey: "LEVEL", Default: "info"}
+		d.Decode(spec, c.Level != "", func(v string) error {
+			return config.ParseString(v, &c.Level)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Mode", Key: "MODE", TagKey: "MODE"}
+		d.Decode(spec, !config.IsZero(c.Mode), func(v string) error {
+			return config.DecodeValue(d.Context(), v, &c.Mode)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Gateway", Key: "GATEWAY", TagKey: "GATEWAY", Lazy: true}
+		d.Decode(spec, !config.IsZero(c.Gateway), func(v string) error {
+			return config.DecodeValue(d.Context(), v, &c.Gateway)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Address", Key: "ADDRESS", TagKey: "ADDRESS", Default: "$HOST:$PORT"}
+		d.Decode(spec, c.Address != "", func(v string) error {
+			return config.ParseString(v, &c.Address)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Database.Host", Key: "DB_HOST", TagKey: "HOST", Default: "localhost"}
+		d.Decode(spec, c.Database.Host != "", func(v string) error {
+			return config.ParseString(v, &c.Database.Host)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Database.Name", Key: "DB_NAME", TagKey: "NAME"}
+		d.Decode(spec, c.Database.Name != "", func(v string) error {
+			return config.ParseString(v, &c.Database.Name)
+		})
+	}
+	{
+		spec := config.FieldSpec{Name: "Database.Password", Key: "DB_PASSWORD", TagKey: "PASSWORD", Secret: true}
+		d.Decode(spec, c.Database.Password != "", func(v string) error {
+			return config.ParseString(v, &c.Database.Password)
+		})
+	}
+}
+
+// EqualConfig implements [config.GeneratedConfig].
+func (c *Config) EqualConfig(other any) bool {
+	o, ok := other.(*Config)
+	if !ok {
+		return false
+	}
+
+	return c.Host == o.Host &&
+		c.Port == o.Port &&
+		c.Debug == o.Debug &&
+		c.Timeout == o.Timeout &&
+		c.Ratio == o.Ratio &&
+		c.MaxConns == o.MaxConns &&
+		slices.Equal(c.Hosts, o.Hosts) &&
+		slices.Equal(c.Ports, o.Ports) &&
+		slices.Equal(c.Token, o.Token) &&
+		c.Pin == o.Pin &&
+		c.Level == o.Level &&
+		reflect.DeepEqual(c.Mode, o.Mode) &&
+		reflect.DeepEqual(c.Gateway, o.Gateway) &&
+		c.Address == o.Address &&
+		c.Database.Host == o.Database.Host &&
+		c.Database.Name == o.Database.Name &&
+		c.Database.Password == o.Database.Password
+}
+
+// ConfigFingerprint implements [config.GeneratedConfig].
+func (c *Config) ConfigFingerprint() string {
+	return "c107871c0cda887c"
+}
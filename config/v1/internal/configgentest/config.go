@@ -0,0 +1,62 @@
+// Package configgentest holds a config struct with methods generated by cmd/configgen,
+// to test and benchmark them against the reflective path.
+package configgentest
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+//go:generate go run github.com/raf555/salome/config/v1/cmd/configgen -type Config
+
+// Config covers every kind of field supported by configgen.
+type Config struct {
+	Host     string        `env:"HOST,required"`
+	Port     int           `env:"PORT,default=8080" validate:"min=1,max=65535"`
+	Debug    bool          `env:"DEBUG"`
+	Timeout  time.Duration `env:"TIMEOUT,default=5s"`
+	Ratio    float64       `env:"RATIO,default=0.5"`
+	MaxConns uint16        `env:"MAX_CONNS,default=0x10"`
+	Hosts    []string      `env:"HOSTS,delimiter=;"`
+	Ports    []int         `env:"PORTS"`
+	Token    []byte        `env:"TOKEN" secret:"true"`
+	Pin      int           `env:"PIN" secret:"true"`
+	Level    Level         `env:"LEVEL,default=info" validate:"oneof=debug info warn error"`
+	Mode     Mode          `env:"MODE"`
+	Gateway  netip.Addr    `env:"GATEWAY"`
+	Address  string        `env:"ADDRESS,default=$HOST:$PORT"`
+	Database Database      `env:",prefix=DB_"`
+}
+
+// Database is a nested config struct.
+type Database struct {
+	Host     string `env:"HOST,default=localhost"`
+	Name     string `env:"NAME" validate:"required"`
+	Password string `env:"PASSWORD" secret:"true"`
+}
+
+// Level is a string based type.
+type Level string
+
+// Mode is a decoder of a non-struct type, which envconfig calls even when unset.
+type Mode int
+
+const (
+	ModeDefault Mode = iota
+	ModeReadOnly
+)
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *Mode) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "", "default":
+		*m = ModeDefault
+	case "readonly":
+		*m = ModeReadOnly
+	default:
+		return fmt.Errorf("unknown mode %q", text)
+	}
+	return nil
+}
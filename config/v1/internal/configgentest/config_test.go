@@ -0,0 +1,255 @@
+package configgentest
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	config "github.com/raf555/salome/config/v1"
+)
+
+// reflective has the fields of Config but not its generated methods.
+type reflective Config
+
+var full = map[string]string{
+	"HOST":        "example.com",
+	"PORT":        "0x1F90",
+	"DEBUG":       "true",
+	"TIMEOUT":     "1m30s",
+	"RATIO":       "0.25",
+	"MAX_CONNS":   "32",
+	"HOSTS":       "a.example.com; b.example.com",
+	"PORTS":       "80,443",
+	"TOKEN":       "s3cr3t",
+	"LEVEL":       "debug",
+	"MODE":        "readonly",
+	"GATEWAY":     "10.0.0.1",
+	"DB_NAME":     "app",
+	"DB_PASSWORD": "hunter2",
+}
+
+func newLoader(t testing.TB) *config.Loader {
+	t.Helper()
+
+	l, err := config.NewLoader(config.WithLookupOrder(config.LookupProviderOnly))
+	if err != nil {
+		t.Fatalf("expecting nil error when creating the loader, got %v", err)
+	}
+	return l
+}
+
+func TestGeneratedMatchesReflective(t *testing.T) {
+	with := func(overrides map[string]string, removed ...string) map[string]string {
+		cfg := map[string]string{}
+		for k, v := range full {
+			cfg[k] = v
+		}
+		for k, v := range overrides {
+			cfg[k] = v
+		}
+		for _, k := range removed {
+			delete(cfg, k)
+		}
+		return cfg
+	}
+
+	tests := []struct {
+		name string
+		cfg  map[string]string
+	}{
+		{name: "full", cfg: full},
+		{name: "defaults", cfg: map[string]string{"HOST": "example.com", "DB_NAME": "app"}},
+		{name: "escaped default", cfg: with(map[string]string{"ADDRESS": ""}, "PORT")},
+		{name: "missing required", cfg: with(nil, "HOST")},
+		{name: "decode errors", cfg: with(map[string]string{
+			"PORT":        "eighty",
+			"PORTS":       "80,http",
+			"MAX_CONNS":   "70000",
+			"MODE":        "admin",
+			"DB_PASSWORD": "",
+			"PIN":         "hunter2",
+		})},
+		{name: "validation errors", cfg: with(map[string]string{"LEVEL": "trace", "PORT": "0"}, "DB_NAME")},
+		{name: "empty values", cfg: with(map[string]string{"PORT": "", "HOSTS": "", "TOKEN": "", "MODE": ""})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLoader(t)
+
+			var generated Config
+			genErr := l.Load(t.Context(), &generated, tt.cfg)
+
+			var expected reflective
+			expectedErr := l.Load(t.Context(), &expected, tt.cfg)
+
+			if !reflect.DeepEqual(Config(expected), generated) {
+				t.Errorf("expecting %+v, got %+v", expected, generated)
+			}
+
+			if expected := fieldErrors(expectedErr); !reflect.DeepEqual(expected, fieldErrors(genErr)) {
+				t.Errorf("expecting errors %v, got %v", expected, fieldErrors(genErr))
+			}
+		})
+	}
+}
+
+// fieldErrors strips the wrapped errors, which differ in their chain.
+func fieldErrors(err error) []config.FieldError {
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) {
+		return nil
+	}
+
+	out := make([]config.FieldError, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		f.Err = nil
+		out = append(out, f)
+	}
+	return out
+}
+
+func TestGeneratedReportsStructDecoderErrors(t *testing.T) {
+	l := newLoader(t)
+
+	// envconfig doesn't tell which field a struct decoder failed on, so the reflective path gives up
+	var cfg Config
+	err := l.Load(t.Context(), &cfg, map[string]string{"HOST": "example.com", "DB_NAME": "app", "GATEWAY": "10.0.0"})
+
+	errs := fieldErrors(err)
+	if len(errs) != 1 || errs[0].Key != "GATEWAY" || errs[0].Rule != "decode" {
+		t.Errorf("expecting a decode error on GATEWAY, got %v", err)
+	}
+}
+
+func TestSecretDecodeErrorsAreRedacted(t *testing.T) {
+	l := newLoader(t)
+	cfg := map[string]string{"HOST": "example.com", "DB_NAME": "app", "PIN": "hunter2"}
+
+	var generated Config
+	var expected reflective
+	for name, err := range map[string]error{
+		"generated":  l.Load(t.Context(), &generated, cfg),
+		"reflective": l.Load(t.Context(), &expected, cfg),
+	} {
+		if !errors.Is(err, config.ErrInvalidSecret) {
+			t.Errorf("%s: expecting config.ErrInvalidSecret, got %v", name, err)
+		}
+		if err != nil && strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("%s: expecting the secret to be redacted, got %v", name, err)
+		}
+	}
+}
+
+// staleConfig has the generated methods of Config, which don't match its fields.
+type staleConfig struct {
+	Config
+	Extra string `env:"EXTRA"`
+}
+
+func TestStaleGeneratedConfig(t *testing.T) {
+	l := newLoader(t)
+	cfg := map[string]string{"HOST": "example.com", "DB_NAME": "app", "EXTRA": "extra"}
+
+	var a, b staleConfig
+	if err := l.Load(t.Context(), &a, cfg); err != nil {
+		t.Fatalf("expecting nil error when loading, got %v", err)
+	}
+	if a.Extra != "extra" || a.Host != "example.com" {
+		t.Errorf("expecting the stale config to be decoded by reflection, got %+v", a)
+	}
+
+	if err := l.Load(t.Context(), &b, cfg); err != nil {
+		t.Fatalf("expecting nil error when loading, got %v", err)
+	}
+	if !config.Equal(&a, &b) {
+		t.Errorf("expecting the stale configs to be compared by reflection")
+	}
+	b.Extra = "other"
+	if config.Equal(&a, &b) {
+		t.Errorf("expecting configs with different fields unknown to the generated code to differ")
+	}
+}
+
+func TestEqualConfig(t *testing.T) {
+	l := newLoader(t)
+
+	var a, b Config
+	if err := l.Load(t.Context(), &a, full); err != nil {
+		t.Fatalf("expecting nil error when loading, got %v", err)
+	}
+	if err := l.Load(t.Context(), &b, full); err != nil {
+		t.Fatalf("expecting nil error when loading, got %v", err)
+	}
+
+	if !a.EqualConfig(&b) {
+		t.Errorf("expecting configs loaded from the same map to be equal")
+	}
+
+	b.Ports[1] = 8443
+	if a.EqualConfig(&b) {
+		t.Errorf("expecting configs with different slices to differ")
+	}
+
+	b = a
+	b.Gateway = netip.MustParseAddr("10.0.0.2")
+	if a.EqualConfig(&b) {
+		t.Errorf("expecting configs with different decoders to differ")
+	}
+
+	if a.EqualConfig(&reflective{}) {
+		t.Errorf("expecting configs of another type to differ")
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	l := newLoader(b)
+
+	b.Run("generated", func(b *testing.B) {
+		for b.Loop() {
+			var dst Config
+			if err := l.Load(b.Context(), &dst, full); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("reflective", func(b *testing.B) {
+		for b.Loop() {
+			var dst reflective
+			if err := l.Load(b.Context(), &dst, full); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkEqual(b *testing.B) {
+	l := newLoader(b)
+
+	var x, y Config
+	if err := l.Load(b.Context(), &x, full); err != nil {
+		b.Fatal(err)
+	}
+	if err := l.Load(b.Context(), &y, full); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("generated", func(b *testing.B) {
+		for b.Loop() {
+			if !x.EqualConfig(&y) {
+				b.Fatal("expecting equal configs")
+			}
+		}
+	})
+
+	b.Run("reflective", func(b *testing.B) {
+		for b.Loop() {
+			if !reflect.DeepEqual(&x, &y) {
+				b.Fatal("expecting equal configs")
+			}
+		}
+	})
+}
@@ -0,0 +1,90 @@
+// Package envtag parses `env` struct tags the same way [github.com/sethvargo/go-envconfig] does.
+package envtag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	optDecodeUnset = "decodeunset"
+	optDefault     = "default="
+	optDelimiter   = "delimiter="
+	optNoInit      = "noinit"
+	optOverwrite   = "overwrite"
+	optPrefix      = "prefix="
+	optRequired    = "required"
+	optSeparator   = "separator="
+)
+
+// ErrUnknownOption is returned when the tag contains an option envconfig doesn't understand.
+var ErrUnknownOption = errors.New("envtag: unknown option")
+
+// Tag is a parsed `env` struct tag.
+type Tag struct {
+	Key         string
+	Default     string
+	Prefix      string
+	Delimiter   string
+	Separator   string
+	Required    bool
+	NoInit      bool
+	Overwrite   bool
+	DecodeUnset bool
+}
+
+// Parse parses the value of an `env` struct tag, e.g. "PORT,default=8080".
+func Parse(tag string) (Tag, error) {
+	parts := splitString(tag, ",", "\\")
+	out := Tag{
+		Key: strings.TrimSpace(parts[0]),
+	}
+	opts := parts[1:]
+
+LOOP:
+	for i, o := range opts {
+		o = strings.TrimLeftFunc(o, unicode.IsSpace)
+		search := strings.ToLower(o)
+
+		switch {
+		case search == optDecodeUnset:
+			out.DecodeUnset = true
+		case search == optOverwrite:
+			out.Overwrite = true
+		case search == optRequired:
+			out.Required = true
+		case search == optNoInit:
+			out.NoInit = true
+		case strings.HasPrefix(search, optPrefix):
+			out.Prefix = strings.TrimPrefix(o, optPrefix)
+		case strings.HasPrefix(search, optDelimiter):
+			out.Delimiter = strings.TrimPrefix(o, optDelimiter)
+		case strings.HasPrefix(search, optSeparator):
+			out.Separator = strings.TrimPrefix(o, optSeparator)
+		case strings.HasPrefix(search, optDefault):
+			// everything after default= is the value, including commas
+			o = strings.TrimLeft(strings.Join(opts[i:], ","), " ")
+			out.Default = strings.TrimPrefix(o, optDefault)
+			break LOOP
+		default:
+			return Tag{}, fmt.Errorf("%w: %q", ErrUnknownOption, o)
+		}
+	}
+
+	return out, nil
+}
+
+// splitString splits s on sep unless sep is escaped by esc.
+func splitString(s, sep, esc string) []string {
+	a := strings.Split(s, sep)
+
+	for i := len(a) - 2; i >= 0; i-- {
+		if strings.HasSuffix(a[i], esc) {
+			a[i] = a[i][:len(a[i])-len(esc)] + sep + a[i+1]
+			a = append(a[:i+1], a[i+2:]...)
+		}
+	}
+	return a
+}
@@ -0,0 +1,58 @@
+package envtag
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		expected Tag
+		err      error
+	}{
+		{
+			name:     "key only",
+			tag:      "PORT",
+			expected: Tag{Key: "PORT"},
+		},
+		{
+			name:     "options",
+			tag:      "HOSTS, required, delimiter=;, separator=|, noinit, overwrite, decodeunset",
+			expected: Tag{Key: "HOSTS", Required: true, Delimiter: ";", Separator: "|", NoInit: true, Overwrite: true, DecodeUnset: true},
+		},
+		{
+			name:     "default keeps commas",
+			tag:      "LIST,default=a,b,c",
+			expected: Tag{Key: "LIST", Default: "a,b,c"},
+		},
+		{
+			name:     "prefix without key",
+			tag:      ",prefix=DB_",
+			expected: Tag{Prefix: "DB_"},
+		},
+		{
+			name:     "escaped comma in key",
+			tag:      `A\,B`,
+			expected: Tag{Key: "A,B"},
+		},
+		{
+			name: "unknown option",
+			tag:  "PORT,foo",
+			err:  ErrUnknownOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, err := Parse(tt.tag)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expecting error %v, got %v", tt.err, err)
+			}
+			if tag != tt.expected {
+				t.Errorf("expecting %+v, got %+v", tt.expected, tag)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Package fingerprint hashes the fields of a config struct, so the code generated by cmd/configgen
+// can be checked against the struct at runtime.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Field is a leaf field of a config struct.
+type Field struct {
+	// Name is the dotted Go path of the field, e.g. "Database.Host".
+	Name string
+	// Key is the fully resolved key, including the prefixes of parent structs.
+	Key string
+	// Type is the type of the field, as reflect.Type.String returns it, e.g. "time.Duration".
+	Type string
+	// Decoder reports whether the type implements one of the decoder interfaces of envconfig.
+	Decoder bool
+	// Tags are the `env` and `secret` tags of the parent structs and of the field, outermost first,
+	// as they're inherited. See [Tag].
+	Tags []string
+}
+
+// Sum returns the fingerprint of the fields, in declaration order.
+func Sum(fields []Field) string {
+	var sb strings.Builder
+	for _, f := range fields {
+		sb.WriteString(strconv.Quote(f.Name))
+		sb.WriteByte(' ')
+		sb.WriteString(strconv.Quote(f.Key))
+		sb.WriteByte(' ')
+		sb.WriteString(strconv.Quote(f.Type))
+		sb.WriteByte(' ')
+		sb.WriteString(strconv.FormatBool(f.Decoder))
+		for _, tag := range f.Tags {
+			sb.WriteByte(' ')
+			sb.WriteString(strconv.Quote(tag))
+		}
+		sb.WriteByte('\n')
+	}
+
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Tag returns the tags of a struct field, as an element of [Field.Tags].
+func Tag(env, secret string) string {
+	return strconv.Quote(env) + strconv.Quote(secret)
+}
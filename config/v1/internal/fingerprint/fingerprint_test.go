@@ -0,0 +1,20 @@
+package fingerprint
+
+import "testing"
+
+func TestSum(t *testing.T) {
+	base := Field{Name: "Mode", Key: "MODE", Type: "app.Mode", Tags: []string{Tag("MODE", "")}}
+
+	decoder := base
+	decoder.Decoder = true
+
+	retyped := base
+	retyped.Type = "string"
+
+	sum := Sum([]Field{base})
+	for name, f := range map[string]Field{"decoder": decoder, "type": retyped} {
+		if Sum([]Field{f}) == sum {
+			t.Errorf("expecting a change of %s to change the fingerprint", name)
+		}
+	}
+}
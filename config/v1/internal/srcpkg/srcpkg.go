@@ -0,0 +1,284 @@
+// Package srcpkg reads the type declarations of a package from its Go source, for the commands
+// working on config structs (cmd/configdoc and cmd/configgen).
+package srcpkg
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DecoderMethods are the methods that make envconfig decode a type as a whole.
+var DecoderMethods = map[string]bool{
+	"EnvDecode":       true,
+	"UnmarshalText":   true,
+	"UnmarshalJSON":   true,
+	"UnmarshalBinary": true,
+	"GobDecode":       true,
+}
+
+// Package holds the type declarations of a parsed package.
+type Package struct {
+	// Name is the name of the package.
+	Name string
+	// Types are the type declarations of the package, by name.
+	Types map[string]*ast.TypeSpec
+	// Decoders are the types of the package with one of the [DecoderMethods].
+	Decoders map[string]bool
+	// Imports are the import paths of the package, by name.
+	Imports map[string]string
+}
+
+// External describes a type declared in another package, see [KnownTypes].
+type External struct {
+	// Struct reports whether the type is a struct.
+	Struct bool
+	// Decoder reports whether the type implements one of the decoder interfaces of envconfig.
+	Decoder bool
+	// Kind is the kind of the type.
+	Kind reflect.Kind
+}
+
+// KnownTypes are the types of the standard library commonly found in config structs, by qualified name
+// (see [Package.QualifiedName]). None of the structs has `env` tags, so envconfig following them finds no field.
+var KnownTypes = map[string]External{
+	"time.Duration":      {Kind: reflect.Int64},
+	"time.Month":         {Kind: reflect.Int},
+	"time.Weekday":       {Kind: reflect.Int},
+	"time.Time":          {Struct: true, Decoder: true, Kind: reflect.Struct},
+	"time.Location":      {Struct: true, Kind: reflect.Struct},
+	"net/url.URL":        {Struct: true, Decoder: true, Kind: reflect.Struct},
+	"net/url.Values":     {Kind: reflect.Map},
+	"net.IP":             {Decoder: true, Kind: reflect.Slice},
+	"net.IPNet":          {Struct: true, Kind: reflect.Struct},
+	"net/netip.Addr":     {Struct: true, Decoder: true, Kind: reflect.Struct},
+	"net/netip.AddrPort": {Struct: true, Decoder: true, Kind: reflect.Struct},
+	"net/netip.Prefix":   {Struct: true, Decoder: true, Kind: reflect.Struct},
+	"math/big.Int":       {Struct: true, Decoder: true, Kind: reflect.Struct},
+	"math/big.Float":     {Struct: true, Decoder: true, Kind: reflect.Struct},
+	"math/big.Rat":       {Struct: true, Decoder: true, Kind: reflect.Struct},
+	"log/slog.Level":     {Decoder: true, Kind: reflect.Int},
+	"regexp.Regexp":      {Struct: true, Decoder: true, Kind: reflect.Struct},
+	"io/fs.FileMode":     {Kind: reflect.Uint32},
+}
+
+// ParseDir parses the package in dir, test files excluded.
+func ParseDir(dir string) (*Package, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("filepath.Glob: %w", err)
+	}
+
+	files = slices.DeleteFunc(files, func(file string) bool {
+		return strings.HasSuffix(file, "_test.go")
+	})
+	return ParseFiles(files)
+}
+
+// ParseFiles parses the files of a package.
+func ParseFiles(files []string) (*Package, error) {
+	pkg := &Package{
+		Types:    map[string]*ast.TypeSpec{},
+		Decoders: map[string]bool{},
+		Imports:  map[string]string{},
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("parser.ParseFile: %w", err)
+		}
+		pkg.Name = f.Name.Name
+
+		for _, spec := range f.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return nil, fmt.Errorf("strconv.Unquote: %w", err)
+			}
+
+			name := importName(importPath)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			pkg.Imports[name] = importPath
+		}
+
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						pkg.Types[ts.Name.Name] = ts
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv != nil && len(decl.Recv.List) == 1 && DecoderMethods[decl.Name.Name] {
+					pkg.Decoders[ReceiverName(decl.Recv.List[0].Type)] = true
+				}
+			}
+		}
+	}
+
+	return pkg, nil
+}
+
+// ReceiverName returns the name of the type of a method receiver or an embedded field.
+func ReceiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return ReceiverName(e.X)
+	case *ast.IndexExpr:
+		return ReceiverName(e.X)
+	case *ast.IndexListExpr:
+		return ReceiverName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// importName returns the name a package is imported with by default, assuming it's the last element
+// of its path without the major version, e.g. "validator" for github.com/go-playground/validator/v10.
+// It holds for the standard library, which [KnownTypes] are from.
+func importName(importPath string) string {
+	dir, name := path.Split(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" && dir != "" {
+		name = path.Base(dir)
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return name
+}
+
+// QualifiedName returns the import path and name of a type of another package, e.g. "net/url.URL".
+// It returns "" if expr doesn't refer to an imported package.
+func (p *Package) QualifiedName(expr *ast.SelectorExpr) string {
+	x, ok := expr.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+
+	importPath, ok := p.Imports[x.Name]
+	if !ok {
+		return ""
+	}
+	return importPath + "." + expr.Sel.Name
+}
+
+// External returns the description of a type of another package if it's one of the [KnownTypes],
+// following pointers.
+func (p *Package) External(expr ast.Expr) (External, bool) {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return p.External(e.X)
+	case *ast.SelectorExpr:
+		ext, ok := KnownTypes[p.QualifiedName(e)]
+		return ext, ok
+	}
+	return External{}, false
+}
+
+// Unresolved returns the type expr is referring to if it may be a struct that can't be followed, i.e. a type
+// of another package that isn't one of the [KnownTypes] or a generic type.
+func (p *Package) Unresolved(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return p.Unresolved(e.X)
+	case *ast.Ident:
+		if _, ok := p.Types[e.Name]; ok || types.Universe.Lookup(e.Name) != nil {
+			return "", false
+		}
+	case *ast.SelectorExpr:
+		if _, ok := p.External(e); ok {
+			return "", false
+		}
+	case *ast.IndexExpr, *ast.IndexListExpr:
+	default:
+		return "", false
+	}
+	return types.ExprString(expr), true
+}
+
+// TypeString returns the type expr is referring to as reflect.Type.String would, e.g. "[]pkg.Level"
+// for []Level in package pkg.
+func (p *Package) TypeString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + p.TypeString(e.X)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return "[]" + p.TypeString(e.Elt)
+		}
+		return "[" + types.ExprString(e.Len) + "]" + p.TypeString(e.Elt)
+	case *ast.MapType:
+		return "map[" + p.TypeString(e.Key) + "]" + p.TypeString(e.Value)
+	case *ast.SelectorExpr:
+		if name := p.QualifiedName(e); name != "" {
+			return importName(strings.TrimSuffix(name, "."+e.Sel.Name)) + "." + e.Sel.Name
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "byte":
+			return "uint8"
+		case "rune":
+			return "int32"
+		case "any":
+			return "interface {}"
+		}
+		if ts, ok := p.Types[e.Name]; ok {
+			if ts.Assign.IsValid() { // alias
+				return p.TypeString(ts.Type)
+			}
+			return p.Name + "." + e.Name
+		}
+	}
+	return types.ExprString(expr)
+}
+
+// IsDecoder reports whether the type expr is referring to implements one of the decoder interfaces
+// of envconfig. Types of other packages that aren't [KnownTypes] are assumed to.
+func (p *Package) IsDecoder(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return p.IsDecoder(e.X)
+	case *ast.SelectorExpr:
+		if ext, ok := p.External(e); ok {
+			return ext.Decoder
+		}
+		return true
+	case *ast.Ident:
+		if ts, ok := p.Types[e.Name]; ok && ts.Assign.IsValid() {
+			return p.IsDecoder(ts.Type)
+		}
+		return p.Decoders[e.Name]
+	}
+	return false
+}
+
+// NestedStruct returns the struct expr is referring to, if it's declared in this package,
+// following pointers.
+func (p *Package) NestedStruct(expr ast.Expr) (string, *ast.StructType) {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return p.NestedStruct(e.X)
+	case *ast.StructType:
+		return "", e
+	case *ast.Ident:
+		if ts, ok := p.Types[e.Name]; ok {
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				return e.Name, st
+			}
+		}
+	}
+	return "", nil
+}
@@ -6,18 +6,25 @@ import (
 )
 
 // LoadConfigTo loads config to T from the provider. The loaded config is the one initially read by the provider.
-func LoadConfigTo[T any](provider Provider) (T, error) {
+// Options can be given to customize parsing and validation.
+func LoadConfigTo[T any](provider Provider, opts ...LoaderOption) (T, error) {
 	ctx := context.TODO()
 
 	var dst T
 
+	l, err := newLoader(opts...)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("newLoader: %w", err)
+	}
+
 	cfg, err := provider.Config(ctx)
 	if err != nil {
 		var zero T
 		return zero, fmt.Errorf("provider.Config: %w", err)
 	}
 
-	if err := loadConfigFromMapTo(ctx, &dst, cfg); err != nil {
+	if err := l.loadConfigFromMapTo(ctx, &dst, cfg); err != nil {
 		var zero T
 		return zero, fmt.Errorf("loadConfigFromMapTo: %w", err)
 	}
@@ -65,3 +72,27 @@ func LoadDynamicConfigToWithNotify[T any](mgr DynamicConfigManager) (DynamicConf
 		adder: adder,
 	}, nil
 }
+
+// Loader parses and validates config maps into config structs. It is safe for concurrent use,
+// and meant for packages building their own loading on top of this one, e.g. config/v2.
+type Loader struct {
+	l *loader
+}
+
+// NewLoader creates a Loader with the given options.
+func NewLoader(opts ...LoaderOption) (*Loader, error) {
+	l, err := newLoader(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("newLoader: %w", err)
+	}
+	return &Loader{l: l}, nil
+}
+
+// Load parses cfg into dst, which must be a pointer to a config struct, and validates it.
+// Invalid fields are reported with a [ValidationError].
+func (l *Loader) Load(ctx context.Context, dst any, cfg map[string]string) error {
+	if err := l.l.loadConfigFromMapTo(ctx, dst, cfg); err != nil {
+		return fmt.Errorf("l.loadConfigFromMapTo: %w", err)
+	}
+	return nil
+}
@@ -1,8 +1,17 @@
 package config
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	"github.com/sethvargo/go-envconfig"
 	"go.uber.org/mock/gomock"
 )
 
@@ -28,6 +37,59 @@ func TestLoadConfigTo(t *testing.T) {
 			t.Errorf("expecting Config.Test to have length 3, got %d", len(conf.Test))
 		}
 	})
+
+	t.Run("error - reports every invalid field", func(t *testing.T) {
+		type Database struct {
+			Port  int    `env:"PORT"`
+			Hosts []int  `env:"HOSTS"`
+			Name  string `env:"NAME" validate:"required"`
+		}
+
+		type Config struct {
+			Required string   `env:"REQUIRED,required"`
+			Test     string   `env:"TEST" validate:"len=3" secret:"true"`
+			Database Database `env:",prefix=DB_"`
+			Valid    string   `env:"VALID" validate:"oneof=a b"`
+		}
+
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{
+			"TEST":     "12",
+			"DB_PORT":  "not-a-port",
+			"DB_HOSTS": "1,x",
+			"VALID":    "a",
+		}, nil)
+
+		_, err := LoadConfigTo[Config](providerMock)
+
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expecting *ValidationError, got %v", err)
+		}
+
+		type result struct{ Key, Field, Value, Rule string }
+		var got []result
+		for _, f := range verr.Fields {
+			got = append(got, result{f.Key, f.Field, f.Value, f.Rule})
+		}
+
+		expected := []result{
+			{"REQUIRED", "Required", "", "required"},
+			{"DB_PORT", "Database.Port", "not-a-port", "decode"},
+			{"DB_HOSTS", "Database.Hosts", "1,x", "decode"},
+			{"TEST", "Test", "******", "len=3"},
+			{"DB_NAME", "Database.Name", "", "required"},
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("expecting fields %+v, got %+v", expected, got)
+		}
+
+		if !errors.Is(err, envconfig.ErrMissingRequired) {
+			t.Errorf("expecting error to wrap envconfig.ErrMissingRequired")
+		}
+	})
 }
 
 func TestLoadDynamicConfigToWithNotify(t *testing.T) {
@@ -122,3 +184,102 @@ func TestLoadDynamicConfigTo(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadConfigToWithLoaderOptions(t *testing.T) {
+	type Config struct {
+		Region string `env:"REGION" validate:"region"`
+		Min    int    `env:"MIN"`
+		Max    int    `env:"MAX"`
+	}
+
+	isRegion := func(_ context.Context, fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "ap-")
+	}
+	minLessThanMax := func(_ context.Context, sl validator.StructLevel) {
+		cfg := sl.Current().Interface().(Config)
+		if cfg.Min >= cfg.Max {
+			sl.ReportError(cfg.Min, "Min", "Min", "ltfield", "Max")
+		}
+	}
+
+	load := func(t *testing.T, cfg map[string]string, opts ...LoaderOption) error {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+		providerMock.EXPECT().Config(gomock.Any()).Return(cfg, nil)
+
+		_, err := LoadConfigTo[Config](providerMock, opts...)
+		return err
+	}
+
+	t.Run("success - custom validations", func(t *testing.T) {
+		err := load(t, map[string]string{"REGION": "ap-southeast-1", "MIN": "1", "MAX": "2"},
+			WithValidation("region", isRegion),
+			WithStructValidation(minLessThanMax, Config{}),
+		)
+		if err != nil {
+			t.Errorf("expecting nil error, got %v", err)
+		}
+	})
+
+	t.Run("error - custom validations", func(t *testing.T) {
+		err := load(t, map[string]string{"REGION": "us-east-1", "MIN": "2", "MAX": "1"},
+			WithValidation("region", isRegion),
+			WithStructValidation(minLessThanMax, Config{}),
+		)
+
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expecting *ValidationError, got %v", err)
+		}
+
+		rules := map[string]string{}
+		for _, f := range verr.Fields {
+			rules[f.Key] = f.Rule
+		}
+		if expected := map[string]string{"REGION": "region", "MIN": "ltfield=Max"}; !reflect.DeepEqual(expected, rules) {
+			t.Errorf("expecting rules %v, got %v", expected, rules)
+		}
+	})
+
+	t.Run("error - custom validations don't leak to the default validator", func(t *testing.T) {
+		err := load(t, map[string]string{"REGION": "ap-southeast-1"})
+		if err == nil {
+			t.Errorf("expecting error for unregistered validation tag")
+		}
+	})
+
+	t.Run("error - translated message", func(t *testing.T) {
+		type Config struct {
+			Name string `env:"NAME" validate:"required"`
+		}
+
+		english := en.New()
+		trans, _ := ut.New(english, english).GetTranslator("en")
+
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{}, nil)
+
+		_, err := LoadConfigTo[Config](providerMock, WithTranslator(trans, entranslations.RegisterDefaultTranslations))
+
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expecting *ValidationError, got %v", err)
+		}
+		if expected := "Name is a required field"; verr.Fields[0].Message != expected {
+			t.Errorf("expecting message %q, got %q", expected, verr.Fields[0].Message)
+		}
+	})
+
+	t.Run("success - own validator", func(t *testing.T) {
+		v := validator.New()
+		if err := v.RegisterValidationCtx("region", isRegion); err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		err := load(t, map[string]string{"REGION": "ap-southeast-1", "MIN": "1", "MAX": "2"}, WithValidator(v))
+		if err != nil {
+			t.Errorf("expecting nil error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"sync"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+// LookupOrder defines where config values are looked up and which source wins.
+type LookupOrder int
+
+const (
+	// LookupOSFirst looks up the OS environment first, then the provider's config.
+	// It is the default, so an OS environment variable overrides the provider's value.
+	LookupOSFirst LookupOrder = iota
+	// LookupProviderFirst looks up the provider's config first, then the OS environment.
+	LookupProviderFirst
+	// LookupProviderOnly looks up the provider's config only.
+	// It is recommended for [Dynamic] with remote providers, as the OS environment never changes
+	// and would otherwise mask remote updates.
+	LookupProviderOnly
+)
+
+// WithLookupOrder sets where config values are looked up. Defaults to [LookupOSFirst].
+func WithLookupOrder(order LookupOrder) LoaderOption {
+	return func(o *loaderOptions) {
+		o.lookupOrder = order
+		o.lookupChain = nil
+	}
+}
+
+// WithLookupChain replaces the lookup order with a custom chain of lookupers, where the first
+// lookuper having the key wins. provider looks up the config of the provider and should be
+// part of the returned chain.
+func WithLookupChain(chain func(provider envconfig.Lookuper) []envconfig.Lookuper) LoaderOption {
+	return func(o *loaderOptions) {
+		o.lookupChain = chain
+	}
+}
+
+// WithShadowCallback registers a callback that is called when a value from the OS environment
+// shadows a different value of the provider, which only happens with [LookupOSFirst].
+// It is called once per key and provider value, and must not block for too long.
+func WithShadowCallback(cb func(key string)) LoaderOption {
+	return func(o *loaderOptions) {
+		o.shadowCallback = cb
+	}
+}
+
+// lookuper builds the lookup chain over the provider's config.
+func (l *loader) lookuper(cfg map[string]string) envconfig.Lookuper {
+	provider := envconfig.MapLookuper(cfg)
+
+	if l.lookupChain != nil {
+		return envconfig.MultiLookuper(l.lookupChain(provider)...)
+	}
+
+	switch l.lookupOrder {
+	case LookupProviderFirst:
+		return envconfig.MultiLookuper(provider, envconfig.OsLookuper())
+	case LookupProviderOnly:
+		return provider
+	}
+
+	os := envconfig.OsLookuper()
+	if l.shadowCallback == nil {
+		return envconfig.MultiLookuper(os, provider)
+	}
+
+	return envconfig.LookuperFunc(func(key string) (string, bool) {
+		osValue, ok := os.Lookup(key)
+		if !ok {
+			return provider.Lookup(key)
+		}
+
+		if providerValue, ok := cfg[key]; ok && providerValue != osValue {
+			l.shadowed.report(key, providerValue, l.shadowCallback)
+		}
+		return osValue, true
+	})
+}
+
+// shadowedKeys dedupes shadow reports by key and provider value.
+type shadowedKeys struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func (s *shadowedKeys) report(key, providerValue string, cb func(string)) {
+	s.mu.Lock()
+	if prev, ok := s.keys[key]; ok && prev == providerValue {
+		s.mu.Unlock()
+		return
+	}
+
+	if s.keys == nil {
+		s.keys = make(map[string]string)
+	}
+	s.keys[key] = providerValue
+	s.mu.Unlock()
+
+	cb(key)
+}
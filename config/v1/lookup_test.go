@@ -0,0 +1,95 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+func TestLoaderLookupOrder(t *testing.T) {
+	type Config struct {
+		Shared   string `env:"SALOME_LOOKUP_SHARED"`
+		OSOnly   string `env:"SALOME_LOOKUP_OS_ONLY"`
+		Provider string `env:"SALOME_LOOKUP_PROVIDER"`
+	}
+
+	t.Setenv("SALOME_LOOKUP_SHARED", "os")
+	t.Setenv("SALOME_LOOKUP_OS_ONLY", "os")
+
+	cfgMap := map[string]string{
+		"SALOME_LOOKUP_SHARED":   "provider",
+		"SALOME_LOOKUP_PROVIDER": "provider",
+	}
+
+	tests := []struct {
+		name     string
+		opts     []LoaderOption
+		expected Config
+	}{
+		{
+			name:     "default is OS first",
+			expected: Config{Shared: "os", OSOnly: "os", Provider: "provider"},
+		},
+		{
+			name:     "provider first",
+			opts:     []LoaderOption{WithLookupOrder(LookupProviderFirst)},
+			expected: Config{Shared: "provider", OSOnly: "os", Provider: "provider"},
+		},
+		{
+			name:     "provider only",
+			opts:     []LoaderOption{WithLookupOrder(LookupProviderOnly)},
+			expected: Config{Shared: "provider", Provider: "provider"},
+		},
+		{
+			name: "custom chain",
+			opts: []LoaderOption{WithLookupChain(func(provider envconfig.Lookuper) []envconfig.Lookuper {
+				return []envconfig.Lookuper{
+					envconfig.MapLookuper(map[string]string{"SALOME_LOOKUP_OS_ONLY": "override"}),
+					provider,
+				}
+			})},
+			expected: Config{Shared: "provider", OSOnly: "override", Provider: "provider"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := newLoader(tt.opts...)
+			if err != nil {
+				t.Fatalf("expecting nil error, got %v", err)
+			}
+
+			var cfg Config
+			if err := l.loadConfigFromMapTo(t.Context(), &cfg, cfgMap); err != nil {
+				t.Fatalf("expecting nil error, got %v", err)
+			}
+			if cfg != tt.expected {
+				t.Errorf("expecting %+v, got %+v", tt.expected, cfg)
+			}
+		})
+	}
+
+	t.Run("shadowed keys are reported once per provider value", func(t *testing.T) {
+		var shadowed []string
+		l, err := newLoader(WithShadowCallback(func(key string) {
+			shadowed = append(shadowed, key)
+		}))
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		for _, value := range []string{"provider", "provider", "os", "provider2"} {
+			var cfg Config
+			cfgMap := map[string]string{"SALOME_LOOKUP_SHARED": value}
+			if err := l.loadConfigFromMapTo(t.Context(), &cfg, cfgMap); err != nil {
+				t.Fatalf("expecting nil error, got %v", err)
+			}
+		}
+
+		expected := []string{"SALOME_LOOKUP_SHARED", "SALOME_LOOKUP_SHARED"}
+		if !reflect.DeepEqual(expected, shadowed) {
+			t.Errorf("expecting shadowed keys %v, got %v", expected, shadowed)
+		}
+	})
+}
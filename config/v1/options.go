@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/sethvargo/go-envconfig"
+)
+
+type loaderOptions struct {
+	validate      *validator.Validate
+	registrations []func(*validator.Validate) error
+	translator    ut.Translator
+
+	decoders  map[reflect.Type]decodeFunc
+	mutators  []envconfig.Mutator
+	delimiter string
+	separator string
+
+	lookupOrder    LookupOrder
+	lookupChain    func(provider envconfig.Lookuper) []envconfig.Lookuper
+	shadowCallback func(key string)
+}
+
+// LoaderOption configures how config is parsed and validated.
+// It is accepted by [LoadConfigTo] and, via [WithLoaderOptions], by [Dynamic].
+type LoaderOption func(*loaderOptions)
+
+// WithValidator validates config with v instead of the package default validator.
+// Registrations made by other options (e.g. [WithValidation]) are applied to v.
+func WithValidator(v *validator.Validate) LoaderOption {
+	return func(o *loaderOptions) {
+		o.validate = v
+	}
+}
+
+// WithValidation registers a custom validation tag, e.g. to validate a region or a CIDR list.
+func WithValidation(tag string, fn validator.FuncCtx, callValidationEvenIfNull ...bool) LoaderOption {
+	return func(o *loaderOptions) {
+		o.registrations = append(o.registrations, func(v *validator.Validate) error {
+			return v.RegisterValidationCtx(tag, fn, callValidationEvenIfNull...)
+		})
+	}
+}
+
+// WithStructValidation registers a struct-level validation for the given types.
+// It is useful for rules spanning multiple fields, e.g. non-overlapping CIDRs.
+func WithStructValidation(fn validator.StructLevelFuncCtx, types ...any) LoaderOption {
+	return func(o *loaderOptions) {
+		o.registrations = append(o.registrations, func(v *validator.Validate) error {
+			v.RegisterStructValidationCtx(fn, types...)
+			return nil
+		})
+	}
+}
+
+// WithTranslator translates validation failures with trans, which is reflected in [FieldError.Message].
+// register is called once to register the translations to the validator, e.g. the RegisterDefaultTranslations
+// function of a package in [github.com/go-playground/validator/v10/translations]. It may be nil.
+func WithTranslator(trans ut.Translator, register func(*validator.Validate, ut.Translator) error) LoaderOption {
+	return func(o *loaderOptions) {
+		o.translator = trans
+		if register != nil {
+			o.registrations = append(o.registrations, func(v *validator.Validate) error {
+				return register(v, trans)
+			})
+		}
+	}
+}
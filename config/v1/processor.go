@@ -2,37 +2,412 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
 
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"github.com/sethvargo/go-envconfig"
 )
 
-func loadConfigFromMapTo(ctx context.Context, dst any, cfg map[string]string) error {
-	if err := processConfig(ctx, dst, cfg); err != nil {
-		return fmt.Errorf("processConfig: %w", err)
+// loader parses and validates config maps into config structs.
+type loader struct {
+	validate   *validator.Validate
+	translator ut.Translator
+
+	decoders  map[reflect.Type]decodeFunc
+	mutators  []envconfig.Mutator
+	delimiter string
+	separator string
+
+	lookupOrder    LookupOrder
+	lookupChain    func(provider envconfig.Lookuper) []envconfig.Lookuper
+	shadowCallback func(key string)
+	shadowed       shadowedKeys
+
+	fieldsCache sync.Map // map[reflect.Type][]configField
+}
+
+func newLoader(opts ...LoaderOption) (*loader, error) {
+	var o loaderOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	validatorOnce.Do(func() {
-		vl = validator.New()
-	})
+	validate := o.validate
+	if validate == nil {
+		validate = defaultValidator()
+		if len(o.registrations) > 0 {
+			// registrations must not leak to the shared validator
+			validate = validator.New()
+		}
+	}
+
+	for _, register := range o.registrations {
+		if err := register(validate); err != nil {
+			return nil, fmt.Errorf("register: %w", err)
+		}
+	}
+
+	return &loader{
+		validate:   validate,
+		translator: o.translator,
+		decoders:   o.decoders,
+		mutators:   o.mutators,
+		delimiter:  o.delimiter,
+		separator:  o.separator,
+
+		lookupOrder:    o.lookupOrder,
+		lookupChain:    o.lookupChain,
+		shadowCallback: o.shadowCallback,
+	}, nil
+}
 
-	if err := vl.StructCtx(ctx, dst); err != nil {
-		return fmt.Errorf("vl.StructCtx: %w", err)
+// configFields is like the package-level configFields, but stops at types with a registered decoder
+// and caches the result per type.
+func (l *loader) configFields(t reflect.Type) ([]configField, error) {
+	if fields, ok := l.fieldsCache.Load(t); ok {
+		return fields.([]configField), nil
+	}
+
+	fields, err := configFields(t, l.hasDecoder)
+	if err != nil {
+		return nil, err
+	}
+
+	l.fieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+// decoder returns the registered decoder of t. A decoder registered for T is also used for *T.
+func (l *loader) decoder(t reflect.Type) (decodeFunc, bool) {
+	if decode, ok := l.decoders[t]; ok {
+		return decode, true
+	}
+
+	if t.Kind() != reflect.Pointer {
+		return nil, false
+	}
+
+	decode, ok := l.decoders[t.Elem()]
+	if !ok {
+		return nil, false
+	}
+
+	return func(ctx context.Context, value string) (reflect.Value, error) {
+		v, err := decode(ctx, value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr, nil
+	}, true
+}
+
+func (l *loader) hasDecoder(t reflect.Type) bool {
+	_, ok := l.decoder(t)
+	return ok
+}
+
+func (l *loader) loadConfigFromMapTo(ctx context.Context, dst any, cfg map[string]string) error {
+	return l.loadConfigFromMapToTracking(ctx, dst, cfg, nil)
+}
+
+// loadConfigFromMapToTracking is like loadConfigFromMapTo, but records every key looked up
+// (including the ones referenced by defaults) to keys, if not nil.
+func (l *loader) loadConfigFromMapToTracking(ctx context.Context, dst any, cfg map[string]string, keys map[string]bool) error {
+	fields, err := l.configFields(reflect.TypeOf(dst))
+	if err != nil {
+		return fmt.Errorf("l.configFields: %w", err)
+	}
+
+	lookuper := l.lookuper(cfg)
+	if keys != nil {
+		next := lookuper
+		lookuper = envconfig.LookuperFunc(func(key string) (string, bool) {
+			keys[key] = true
+			return next.Lookup(key)
+		})
+	}
+
+	verr := &ValidationError{}
+
+	var (
+		failed   map[string]bool
+		complete bool
+	)
+	if gen, ok := dst.(GeneratedConfig); ok && len(l.decoders) == 0 && upToDate(gen) {
+		failed, complete = l.decodeGenerated(ctx, gen, lookuper, verr), true
+	} else {
+		failed, complete, err = l.processConfig(ctx, dst, lookuper, fields, verr)
+		if err != nil {
+			return fmt.Errorf("l.processConfig: %w", err)
+		}
+
+		if complete {
+			l.decodeCustomFields(ctx, dst, lookuper, fields, failed, verr)
+		}
+	}
+
+	// validating a partially decoded struct only adds noise
+	if complete {
+		if err := l.validateConfig(ctx, dst, lookuper, fields, failed, verr); err != nil {
+			return fmt.Errorf("validateConfig: %w", err)
+		}
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
 	}
 
 	return nil
 }
 
-func processConfig(ctx context.Context, dst any, cfg map[string]string) error {
-	if err := envconfig.ProcessWith(ctx, &envconfig.Config{
-		Target: dst,
-		Lookuper: envconfig.MultiLookuper(
-			envconfig.OsLookuper(), // if env is specified, it takes precedence
-			envconfig.MapLookuper(cfg),
-		),
-	}); err != nil {
-		return fmt.Errorf("envconfig.Process: %w", err)
+// processConfig decodes the config into dst. Fields that fail to decode are recorded to verr and
+// skipped on the next attempt, so every decode failure is reported at once.
+// Fields with a registered decoder are skipped as well, they are decoded by decodeCustomFields.
+// It returns the keys of the failed fields and whether every other field has been decoded.
+func (l *loader) processConfig(ctx context.Context, dst any, lookuper envconfig.Lookuper, fields []configField, verr *ValidationError) (map[string]bool, bool, error) {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return nil, false, fmt.Errorf("envconfig.Process: %w", envconfig.ErrNotPtr)
 	}
+	target = target.Elem()
+
+	initial := reflect.New(target.Type()).Elem()
+	initial.Set(target)
+
+	custom := make(map[string]bool)
+	for _, f := range fields {
+		if l.hasDecoder(f.Field.Type) {
+			custom[f.Key] = true
+		}
+	}
+
+	failed := make(map[string]bool)
+	skip := envconfig.LookuperFunc(func(key string) (string, bool) {
+		if failed[key] || custom[key] {
+			return "", true
+		}
+		return lookuper.Lookup(key)
+	})
+
+	for {
+		err := envconfig.ProcessWith(ctx, &envconfig.Config{
+			Target:           dst,
+			Lookuper:         skip,
+			DefaultDelimiter: l.delimiter,
+			DefaultSeparator: l.separator,
+			Mutators:         l.mutators,
+		})
+		if err == nil {
+			return failed, true, nil
+		}
+
+		fieldErr, ok := decodeFieldError(err, fields, lookuper)
+		if !ok {
+			return nil, false, fmt.Errorf("envconfig.Process: %w", err)
+		}
+
+		if failed[fieldErr.Key] {
+			// the field can't be skipped (its decoder rejects empty values), give up on the remaining fields
+			return failed, false, nil
+		}
+
+		failed[fieldErr.Key] = true
+		verr.Fields = append(verr.Fields, fieldErr)
+
+		target.Set(initial)
+	}
+}
+
+// decodeGenerated decodes the config with its generated DecodeConfig, which reports every decode failure
+// at once by itself. It returns the keys of the failed fields.
+func (l *loader) decodeGenerated(ctx context.Context, gen GeneratedConfig, lookuper envconfig.Lookuper, verr *ValidationError) map[string]bool {
+	d := &FieldDecoder{
+		ctx:      ctx,
+		l:        l,
+		lookuper: lookuper,
+		failed:   make(map[string]bool),
+		verr:     verr,
+	}
+	gen.DecodeConfig(d)
+	return d.failed
+}
+
+// decodeCustomFields decodes the fields with a registered decoder, mirroring envconfig's handling
+// of the required and default options and mutators.
+func (l *loader) decodeCustomFields(ctx context.Context, dst any, lookuper envconfig.Lookuper, fields []configField, failed map[string]bool, verr *ValidationError) {
+	root := reflect.ValueOf(dst)
+
+	for _, f := range fields {
+		decode, ok := l.decoder(f.Field.Type)
+		if !ok {
+			continue
+		}
+
+		value, found := lookuper.Lookup(f.Key)
+		if !found {
+			if f.Required {
+				failed[f.Key] = true
+				verr.Fields = append(verr.Fields, FieldError{
+					Field:   f.Name,
+					Key:     f.Key,
+					Rule:    ruleRequired,
+					Message: "missing required value",
+					Err:     fmt.Errorf("%w: %s", envconfig.ErrMissingRequired, f.Key),
+				})
+				continue
+			}
+
+			if f.Tag.Default == "" {
+				continue
+			}
+
+			value = os.Expand(f.Tag.Default, func(key string) string {
+				v, _ := lookuper.Lookup(key)
+				return v
+			})
+		}
+
+		v, err := l.decodeCustomField(ctx, f, value, decode)
+		if err != nil {
+			msg, err := redactDecodeError(f.Secret, f.Name, err.Error(), fmt.Errorf("%s: %w", f.Name, err))
+
+			failed[f.Key] = true
+			verr.Fields = append(verr.Fields, FieldError{
+				Field:   f.Name,
+				Key:     f.Key,
+				Value:   redactValue(f, value),
+				Rule:    ruleDecode,
+				Message: msg,
+				Err:     err,
+			})
+			continue
+		}
+
+		// a nil parent means the struct is left uninitialized on purpose (noinit)
+		if fv, ok := f.value(root); ok {
+			fv.Set(v)
+		}
+	}
+}
+
+func (l *loader) decodeCustomField(ctx context.Context, f configField, value string, decode decodeFunc) (reflect.Value, error) {
+	original := value
+	for _, mu := range l.mutators {
+		var (
+			stop bool
+			err  error
+		)
+		value, stop, err = mu.EnvMutate(ctx, f.Tag.Key, f.Key, original, value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if stop {
+			break
+		}
+	}
+
+	return decode(ctx, value)
+}
+
+// decodeFieldError maps an envconfig error to the field that caused it.
+func decodeFieldError(err error, fields []configField, lookuper envconfig.Lookuper) (FieldError, bool) {
+	path, errs := errorFieldPath(err)
+
+	// decode errors of slice and map items add the item as another segment, so find the longest match
+	for n := len(path); n > 0; n-- {
+		field, ok := findConfigField(fields, strings.Join(path[:n], "."))
+		if !ok {
+			continue
+		}
+
+		cause := errs[n]
+		rule, msg := ruleDecode, cause.Error()
+		if errors.Is(cause, envconfig.ErrMissingRequired) {
+			rule, msg = ruleRequired, "missing required value"
+		} else {
+			msg, err = redactDecodeError(field.Secret, field.Name, msg, err)
+		}
+
+		value, _ := lookuper.Lookup(field.Key)
+
+		return FieldError{
+			Field:   field.Name,
+			Key:     field.Key,
+			Value:   redactValue(field, value),
+			Rule:    rule,
+			Message: msg,
+			Err:     err,
+		}, true
+	}
+
+	return FieldError{}, false
+}
+
+func (l *loader) validateConfig(ctx context.Context, dst any, lookuper envconfig.Lookuper, fields []configField, failed map[string]bool, verr *ValidationError) error {
+	err := l.structCtx(ctx, dst)
+	if err == nil {
+		return nil
+	}
+
+	var vErrs validator.ValidationErrors
+	if !errors.As(err, &vErrs) {
+		return fmt.Errorf("l.validate.StructCtx: %w", err)
+	}
+
+	for _, fe := range vErrs {
+		// namespace is prefixed with the name of the top-level struct
+		_, name, _ := strings.Cut(fe.StructNamespace(), ".")
+
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+
+		msg := fmt.Sprintf("failed on the %q rule", rule)
+		if l.translator != nil {
+			msg = fe.Translate(l.translator)
+		}
+
+		fieldErr := FieldError{
+			Field:   name,
+			Rule:    rule,
+			Message: msg,
+			Err:     fe,
+		}
+
+		// dive errors are reported on the item, e.g. "Hosts[0]"
+		fieldName, _, _ := strings.Cut(name, "[")
+		if field, ok := findConfigField(fields, fieldName); ok {
+			if failed[field.Key] {
+				continue
+			}
+
+			value, _ := lookuper.Lookup(field.Key)
+			fieldErr.Key = field.Key
+			fieldErr.Value = redactValue(field, value)
+		}
+
+		verr.Fields = append(verr.Fields, fieldErr)
+	}
+
 	return nil
 }
+
+// structCtx validates dst, turning the panics of validator (e.g. on an unregistered tag) into errors.
+func (l *loader) structCtx(ctx context.Context, dst any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("validator panic: %v", r)
+		}
+	}()
+	return l.validate.StructCtx(ctx, dst)
+}
@@ -1,6 +1,11 @@
 package config
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
 
 //go:generate go tool mockgen -typed -source provider.go -destination provider.mock.gen.go -package config
 
@@ -11,6 +16,46 @@ type Provider interface {
 	FetchConfig(ctx context.Context) (map[string]string, error)
 }
 
+// ContextCloser is implemented by providers that hold resources which must be released
+// with a context, e.g. background token refreshers or open connections.
+// Providers that only need a plain Close can implement [io.Closer] instead.
+type ContextCloser interface {
+	Close(ctx context.Context) error
+}
+
+// CloseProvider releases the resources held by the provider if it implements
+// [ContextCloser] or [io.Closer]. It is a no-op for any other provider.
+func CloseProvider(ctx context.Context, provider Provider) error {
+	switch p := provider.(type) {
+	case ContextCloser:
+		return p.Close(ctx)
+	case io.Closer:
+		return p.Close()
+	}
+	return nil
+}
+
+// CloseProviders closes the providers in the given order using [CloseProvider].
+// Every provider is closed even if a previous one failed; the errors are joined.
+// It is meant to be used by composite providers that own other providers.
+func CloseProviders(ctx context.Context, providers ...Provider) error {
+	var errs []error
+	for i, provider := range providers {
+		if err := CloseProvider(ctx, provider); err != nil {
+			errs = append(errs, fmt.Errorf("providers[%d]: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SourceAnnotator is implemented by providers that can tell where each key of their config comes from,
+// e.g. "os" or "dotenv:.env". Composite providers should report the source of the provider whose value won.
+// It is used by [ExplainConfig] and [Dynamic.Explain].
+type SourceAnnotator interface {
+	// Source returns the source of the key in the config last read by the provider, or "" if unknown.
+	Source(key string) string
+}
+
 type DynamicConfigManager interface {
 	// GetConfig provides a config from the provided key.
 	// It may return nil if not found.
@@ -41,6 +86,8 @@ type DynamicConfigGetter[T any] interface {
 type DynamicConfigGetterWithNotify[T any] interface {
 	DynamicConfigGetter[T]
 
+	// RegisterCallback registers a callback called with the config whenever it changes.
+	// The callback must not call [Dynamic.Close] synchronously, see its restrictions.
 	RegisterCallback(func(T))
 }
 
@@ -118,6 +118,68 @@ func (c *MockProviderFetchConfigCall) DoAndReturn(f func(context.Context) (map[s
 	return c
 }
 
+// MockContextCloser is a mock of ContextCloser interface.
+type MockContextCloser struct {
+	ctrl     *gomock.Controller
+	recorder *MockContextCloserMockRecorder
+	isgomock struct{}
+}
+
+// MockContextCloserMockRecorder is the mock recorder for MockContextCloser.
+type MockContextCloserMockRecorder struct {
+	mock *MockContextCloser
+}
+
+// NewMockContextCloser creates a new mock instance.
+func NewMockContextCloser(ctrl *gomock.Controller) *MockContextCloser {
+	mock := &MockContextCloser{ctrl: ctrl}
+	mock.recorder = &MockContextCloserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContextCloser) EXPECT() *MockContextCloserMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockContextCloser) Close(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockContextCloserMockRecorder) Close(ctx any) *MockContextCloserCloseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockContextCloser)(nil).Close), ctx)
+	return &MockContextCloserCloseCall{Call: call}
+}
+
+// MockContextCloserCloseCall wrap *gomock.Call
+type MockContextCloserCloseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockContextCloserCloseCall) Return(arg0 error) *MockContextCloserCloseCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockContextCloserCloseCall) Do(f func(context.Context) error) *MockContextCloserCloseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockContextCloserCloseCall) DoAndReturn(f func(context.Context) error) *MockContextCloserCloseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // MockDynamicConfigManager is a mock of DynamicConfigManager interface.
 type MockDynamicConfigManager struct {
 	ctrl     *gomock.Controller
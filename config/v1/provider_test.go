@@ -0,0 +1,39 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+type plainCloserProvider struct {
+	*MockProvider
+	err error
+}
+
+func (p plainCloserProvider) Close() error {
+	return p.err
+}
+
+func TestCloseProviders(t *testing.T) {
+	t.Run("closes every provider and joins errors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		errFirst := errors.New("first")
+		errThird := errors.New("third")
+
+		first := NewMockContextCloser(ctrl)
+		first.EXPECT().Close(gomock.Any()).Return(errFirst).Times(1)
+
+		err := CloseProviders(t.Context(),
+			closableProvider{NewMockProvider(ctrl), first},
+			NewMockProvider(ctrl), // not closable, skipped
+			plainCloserProvider{NewMockProvider(ctrl), errThird},
+		)
+
+		if !errors.Is(err, errFirst) || !errors.Is(err, errThird) {
+			t.Errorf("expecting joined errors of first and third providers, got %v", err)
+		}
+	})
+}
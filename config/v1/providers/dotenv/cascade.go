@@ -0,0 +1,175 @@
+package dotenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultEnvKey is the key naming the environment of the cascade, e.g. APP_ENV=production.
+const DefaultEnvKey = "APP_ENV"
+
+// ErrInvalidEnvironment is returned when the environment of the cascade can't be part of a file name,
+// i.e. it contains a path separator or "..".
+var ErrInvalidEnvironment = errors.New("dotenv: invalid environment")
+
+// CascadeProvider is a config provider from a cascade of .env files, in increasing precedence:
+//
+//	.env
+//	.env.local
+//	.env.<environment>
+//	.env.<environment>.local
+//
+// Missing files are skipped. The environment is read from the OS, or else from .env and .env.local.
+// Without an environment, only .env and .env.local are loaded.
+type CascadeProvider struct {
+	dir    string
+	envKey string
+	env    string
+
+	initial map[string]string
+
+	mu          sync.RWMutex
+	environment string            // environment of the last fetch
+	loaded      []string          // files of the last fetch, in increasing precedence
+	sources     map[string]string // file of every key of the last fetch
+}
+
+// CascadeOption configures a [CascadeProvider].
+type CascadeOption func(*CascadeProvider)
+
+// WithEnvKey sets the key naming the environment, [DefaultEnvKey] by default.
+func WithEnvKey(key string) CascadeOption {
+	return func(c *CascadeProvider) {
+		c.envKey = key
+	}
+}
+
+// WithEnvironment sets the environment instead of reading it from the OS and the base files.
+func WithEnvironment(env string) CascadeOption {
+	return func(c *CascadeProvider) {
+		c.env = env
+	}
+}
+
+// NewCascade creates a provider from the cascade of .env files in dir.
+func NewCascade(dir string, opts ...CascadeOption) (*CascadeProvider, error) {
+	provider := &CascadeProvider{
+		dir:    dir,
+		envKey: DefaultEnvKey,
+	}
+	for _, opt := range opts {
+		opt(provider)
+	}
+
+	initialCfg, err := provider.FetchConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("provider.FetchConfig: %w", err)
+	}
+	provider.initial = initialCfg
+
+	return provider, nil
+}
+
+func (c *CascadeProvider) Config(_ context.Context) (map[string]string, error) {
+	return maps.Clone(c.initial), nil
+}
+
+func (c *CascadeProvider) FetchConfig(_ context.Context) (map[string]string, error) {
+	out := map[string]string{}
+	sources := map[string]string{}
+	var loaded []string
+
+	load := func(name string) error {
+		filename := filepath.Join(c.dir, name)
+
+		cfg, err := readFile(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+
+		maps.Copy(out, cfg)
+		for key := range cfg {
+			sources[key] = filename
+		}
+		loaded = append(loaded, filename)
+		return nil
+	}
+
+	for _, name := range []string{".env", ".env.local"} {
+		if err := load(name); err != nil {
+			return nil, err
+		}
+	}
+
+	env := c.resolveEnvironment(out)
+	if strings.ContainsAny(env, `/\`) || strings.Contains(env, "..") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidEnvironment, env)
+	}
+	if env != "" {
+		for _, name := range []string{".env." + env, ".env." + env + ".local"} {
+			if err := load(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.environment = env
+	c.loaded = loaded
+	c.sources = sources
+	c.mu.Unlock()
+
+	return out, nil
+}
+
+// resolveEnvironment returns the environment of the cascade, given the config of the base files.
+func (c *CascadeProvider) resolveEnvironment(base map[string]string) string {
+	if c.env != "" {
+		return c.env
+	}
+	if env, ok := os.LookupEnv(c.envKey); ok {
+		return env
+	}
+	return base[c.envKey]
+}
+
+// Environment returns the environment of the last fetch, "" if none.
+func (c *CascadeProvider) Environment() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.environment
+}
+
+// Precedence returns the files loaded in the last fetch, in increasing precedence:
+// a key is taken from the last file defining it.
+func (c *CascadeProvider) Precedence() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.loaded)
+}
+
+// Source annotates the key with the file its value came from in the last fetch, e.g. "dotenv:.env.local".
+// It implements config.SourceAnnotator.
+func (c *CascadeProvider) Source(key string) string {
+	c.mu.RLock()
+	filename, ok := c.sources[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return ""
+	}
+	return "dotenv:" + filename
+}
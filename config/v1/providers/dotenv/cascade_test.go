@@ -0,0 +1,146 @@
+package dotenv_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raf555/salome/config/v1/providers/dotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+}
+
+func TestCascade(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".env":                  "A=env\nB=env\nC=env\nD=env\nAPP_ENV=staging\n",
+		".env.local":            "B=local\nC=local\nD=local\n",
+		".env.staging":          "C=staging\nD=staging\n",
+		".env.staging.local":    "D=staging.local\n",
+		".env.production":       "A=production\n",
+		".env.production.local": "A=production.local\n",
+	})
+
+	provider, err := dotenv.NewCascade(dir)
+	assert.NoError(t, err)
+
+	cfg, err := provider.Config(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"A":       "env",
+		"B":       "local",
+		"C":       "staging",
+		"D":       "staging.local",
+		"APP_ENV": "staging",
+	}, cfg)
+
+	assert.Equal(t, "staging", provider.Environment())
+	assert.Equal(t, []string{
+		filepath.Join(dir, ".env"),
+		filepath.Join(dir, ".env.local"),
+		filepath.Join(dir, ".env.staging"),
+		filepath.Join(dir, ".env.staging.local"),
+	}, provider.Precedence())
+
+	assert.Equal(t, "dotenv:"+filepath.Join(dir, ".env"), provider.Source("A"))
+	assert.Equal(t, "dotenv:"+filepath.Join(dir, ".env.staging.local"), provider.Source("D"))
+	assert.Equal(t, "", provider.Source("MISSING"))
+}
+
+func TestCascadeEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".env":             "A=env\nAPP_ENV=staging\nMODE=env\n",
+		".env.production":  "A=production\n",
+		".env.development": "A=development\n",
+	})
+
+	t.Run("from the OS", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+
+		provider, err := dotenv.NewCascade(dir)
+		assert.NoError(t, err)
+
+		cfg, _ := provider.Config(t.Context())
+		assert.Equal(t, "production", cfg["A"])
+		assert.Equal(t, "production", provider.Environment())
+	})
+
+	t.Run("with a missing file", func(t *testing.T) {
+		provider, err := dotenv.NewCascade(dir)
+		assert.NoError(t, err)
+
+		cfg, _ := provider.Config(t.Context())
+		assert.Equal(t, "env", cfg["A"])
+		assert.Equal(t, []string{filepath.Join(dir, ".env")}, provider.Precedence())
+	})
+
+	t.Run("with another key", func(t *testing.T) {
+		writeFiles(t, dir, map[string]string{".env.local": "MODE=development\n"})
+		defer os.Remove(filepath.Join(dir, ".env.local"))
+
+		provider, err := dotenv.NewCascade(dir, dotenv.WithEnvKey("MODE"))
+		assert.NoError(t, err)
+
+		cfg, _ := provider.Config(t.Context())
+		assert.Equal(t, "development", cfg["A"])
+	})
+
+	t.Run("fixed", func(t *testing.T) {
+		provider, err := dotenv.NewCascade(dir, dotenv.WithEnvironment("development"))
+		assert.NoError(t, err)
+
+		cfg, _ := provider.Config(t.Context())
+		assert.Equal(t, "development", cfg["A"])
+	})
+
+	t.Run("none", func(t *testing.T) {
+		provider, err := dotenv.NewCascade(t.TempDir())
+		assert.NoError(t, err)
+
+		cfg, _ := provider.Config(t.Context())
+		assert.Empty(t, cfg)
+		assert.Empty(t, provider.Precedence())
+		assert.Equal(t, "", provider.Environment())
+	})
+}
+
+func TestCascadeFetchConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{".env": "A=env\n"})
+
+	provider, err := dotenv.NewCascade(dir, dotenv.WithEnvironment("test"))
+	assert.NoError(t, err)
+
+	writeFiles(t, dir, map[string]string{".env.test.local": "A=test.local\n"})
+
+	cfg, err := provider.FetchConfig(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "test.local", cfg["A"])
+	assert.Len(t, provider.Precedence(), 2)
+
+	writeFiles(t, dir, map[string]string{".env.test": "A='unterminated\n"})
+
+	_, err = provider.FetchConfig(t.Context())
+	assert.Error(t, err)
+}
+
+func TestCascadeInvalidEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{".env": "APP_ENV=../secrets\n"})
+
+	_, err := dotenv.NewCascade(dir)
+	assert.True(t, errors.Is(err, dotenv.ErrInvalidEnvironment))
+
+	for _, env := range []string{"prod/eu", `prod\eu`, "..", "prod..eu"} {
+		_, err := dotenv.NewCascade(t.TempDir(), dotenv.WithEnvironment(env))
+		assert.True(t, errors.Is(err, dotenv.ErrInvalidEnvironment), env)
+	}
+}
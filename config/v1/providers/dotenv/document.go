@@ -0,0 +1,396 @@
+package dotenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ErrSyntax is returned when a .env document can't be parsed.
+var ErrSyntax = errors.New("dotenv: syntax error")
+
+// Document is a .env file that can be edited and written back, preserving its comments, blank lines,
+// ordering and quoting style. Its values are read as godotenv does, but variables aren't expanded.
+//
+// The zero value is an empty document.
+type Document struct {
+	nodes []docNode
+	crlf  bool
+	// noFinalNewline is set when the parsed document doesn't end with a line break.
+	noFinalNewline bool
+}
+
+// docNode is an entry, a comment or a blank line.
+type docNode struct {
+	// raw is the text of the node without its final line break, kept as is unless the value is set.
+	raw string
+
+	// key is empty for comments and blank lines.
+	key    string
+	value  string
+	quote  byte // 0, '\'' or '"'
+	prefix string
+	sep    string
+	suffix string
+}
+
+// ReadDocument parses the .env file.
+func ReadDocument(filename string) (*Document, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return ParseDocument(file)
+}
+
+// ParseDocument parses a .env document.
+func ParseDocument(r io.Reader) (*Document, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll: %w", err)
+	}
+
+	d := &Document{crlf: bytes.Contains(src, []byte("\r\n"))}
+	text := strings.ReplaceAll(string(src), "\r\n", "\n")
+	if text == "" {
+		return d, nil
+	}
+
+	d.noFinalNewline = !strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+
+	for line := 1; ; {
+		node, err := parseNode(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrSyntax, line, err)
+		}
+
+		d.nodes = append(d.nodes, node)
+		line += strings.Count(node.raw, "\n") + 1
+
+		if len(node.raw) == len(text) {
+			return d, nil
+		}
+		text = text[len(node.raw)+1:]
+	}
+}
+
+// parseNode parses the node at the start of text, up to the line break ending it.
+func parseNode(text string) (docNode, error) {
+	lineEnd := strings.IndexByte(text, '\n')
+	if lineEnd == -1 {
+		lineEnd = len(text)
+	}
+	line := text[:lineEnd]
+
+	trimmed := strings.TrimLeftFunc(line, unicode.IsSpace)
+	if trimmed == "" || trimmed[0] == '#' {
+		return docNode{raw: line}, nil
+	}
+
+	// prefix: indentation and export
+	pos := len(line) - len(trimmed)
+	if rest, ok := strings.CutPrefix(trimmed, "export"); ok && rest != "" && isSpace(rune(rest[0])) {
+		pos = len(line) - len(strings.TrimLeftFunc(rest, isSpace))
+	}
+	prefix := line[:pos]
+
+	keyEnd := pos
+	for keyEnd < len(line) && isKeyChar(rune(line[keyEnd])) {
+		keyEnd++
+	}
+	key := line[pos:keyEnd]
+	if key == "" {
+		return docNode{}, fmt.Errorf("missing key in %q", line)
+	}
+
+	sepEnd := keyEnd
+	for sepEnd < len(line) && isSpace(rune(line[sepEnd])) {
+		sepEnd++
+	}
+	if sepEnd == len(line) || (line[sepEnd] != '=' && line[sepEnd] != ':') {
+		return docNode{}, fmt.Errorf("unexpected character after key %s in %q", key, line)
+	}
+	sepEnd++
+	for sepEnd < len(line) && isSpace(rune(line[sepEnd])) {
+		sepEnd++
+	}
+
+	node := docNode{
+		key:    key,
+		prefix: prefix,
+		sep:    line[keyEnd:sepEnd],
+	}
+
+	if sepEnd < len(line) && (line[sepEnd] == '\'' || line[sepEnd] == '"') {
+		// quoted values may span multiple lines
+		quote := line[sepEnd]
+		end := closingQuote(text, sepEnd, quote)
+		if end == -1 {
+			return docNode{}, fmt.Errorf("unterminated quoted value of %s", key)
+		}
+
+		valueLineEnd := strings.IndexByte(text[end:], '\n')
+		if valueLineEnd == -1 {
+			valueLineEnd = len(text)
+		} else {
+			valueLineEnd += end
+		}
+
+		node.quote = quote
+		node.value = unquote(text[sepEnd+1:end], quote)
+		node.suffix = text[end+1 : valueLineEnd]
+		node.raw = text[:valueLineEnd]
+		return node, nil
+	}
+
+	// unquoted values end at a comment preceded by a space, as godotenv does
+	value := line[sepEnd:]
+	for i := len(value) - 1; i > 0; i-- {
+		if value[i] == '#' && isSpace(rune(value[i-1])) {
+			value = value[:i]
+			break
+		}
+	}
+	value = strings.TrimRightFunc(value, isSpace)
+
+	node.value = value
+	node.suffix = line[sepEnd+len(value):]
+	node.raw = line
+	return node, nil
+}
+
+// closingQuote returns the index of the quote closing the one at start, skipping escaped quotes.
+// A quote is escaped by an odd run of backslashes, e.g. \" but not \\".
+func closingQuote(text string, start int, quote byte) int {
+	for i := start + 1; i < len(text); i++ {
+		if text[i] != quote {
+			continue
+		}
+
+		backslashes := 0
+		for j := i - 1; j > start && text[j] == '\\'; j-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote decodes a quoted value: single-quoted values are literal, double-quoted values
+// have their escapes decoded.
+func unquote(s string, quote byte) string {
+	if quote == '\'' {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// Get returns the value of the key. If the key is set more than once, the last value wins.
+func (d *Document) Get(key string) (string, bool) {
+	i := d.index(key)
+	if i == -1 {
+		return "", false
+	}
+	return d.nodes[i].value, true
+}
+
+// Keys returns the keys of the document in order, once each.
+func (d *Document) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, n := range d.nodes {
+		if n.key != "" && !seen[n.key] {
+			seen[n.key] = true
+			keys = append(keys, n.key)
+		}
+	}
+	return keys
+}
+
+// Map returns the values of the document by key.
+func (d *Document) Map() map[string]string {
+	out := make(map[string]string)
+	for _, n := range d.nodes {
+		if n.key != "" {
+			out[n.key] = n.value
+		}
+	}
+	return out
+}
+
+// Set sets the value of the key, keeping its position, its comment and its quoting style if the value
+// allows it. New keys are appended to the end of the document.
+func (d *Document) Set(key, value string) {
+	i := d.index(key)
+	if i == -1 {
+		d.nodes = append(d.nodes, docNode{key: key, sep: "="})
+		i = len(d.nodes) - 1
+	}
+
+	n := &d.nodes[i]
+	n.value = value
+	n.quote = quoteFor(value, n.quote)
+	n.raw = n.prefix + n.key + n.sep + quote(value, n.quote) + n.suffix
+}
+
+// Unset removes every entry of the key. It reports whether the key was set.
+func (d *Document) Unset(key string) bool {
+	found := false
+	nodes := d.nodes[:0]
+	for _, n := range d.nodes {
+		if n.key == key {
+			found = true
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	d.nodes = nodes
+	return found
+}
+
+func (d *Document) index(key string) int {
+	for i := len(d.nodes) - 1; i >= 0; i-- {
+		if d.nodes[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteTo writes the document to w. It implements io.WriterTo.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	lineBreak := "\n"
+	if d.crlf {
+		lineBreak = "\r\n"
+	}
+
+	var b strings.Builder
+	for i, n := range d.nodes {
+		b.WriteString(strings.ReplaceAll(n.raw, "\n", lineBreak))
+		if i < len(d.nodes)-1 || !d.noFinalNewline {
+			b.WriteString(lineBreak)
+		}
+	}
+
+	written, err := io.WriteString(w, b.String())
+	return int64(written), err
+}
+
+// WriteFile writes the document to the file, replacing it atomically and keeping its permissions.
+func (d *Document) WriteFile(filename string) error {
+	perm := fs.FileMode(0o644)
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := d.WriteTo(tmp); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("d.WriteTo: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("tmp.Chmod: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("tmp.Close: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+	return nil
+}
+
+// quoteFor returns the quoting style of the value: the current one if it can represent the value,
+// otherwise no quotes, single quotes or double quotes, whichever can first.
+func quoteFor(value string, current byte) byte {
+	if canQuote(value, current) {
+		return current
+	}
+	for _, q := range []byte{0, '\'', '"'} {
+		if canQuote(value, q) {
+			return q
+		}
+	}
+	return '"'
+}
+
+// canQuote reports whether godotenv reads the value back as is with the quoting style.
+func canQuote(value string, quote byte) bool {
+	switch quote {
+	case 0:
+		return strings.TrimFunc(value, unicode.IsSpace) == value &&
+			!strings.ContainsAny(value, "\n\r#$\\") &&
+			(value == "" || (value[0] != '\'' && value[0] != '"'))
+	case '\'':
+		// a trailing backslash would escape the closing quote
+		return !strings.ContainsAny(value, "'\r") && !strings.HasSuffix(value, `\`)
+	default:
+		// godotenv trims the quotes around the value, escaped or not
+		return !strings.HasPrefix(value, `"`) && !strings.HasSuffix(value, `"`)
+	}
+}
+
+func quote(value string, quote byte) string {
+	switch quote {
+	case 0:
+		return value
+	case '\'':
+		return "'" + value + "'"
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// isSpace reports whether the rune is a space but not a line break, as godotenv does.
+func isSpace(r rune) bool {
+	switch r {
+	case '\t', '\v', '\f', '\r', ' ', 0x85, 0xA0:
+		return true
+	}
+	return false
+}
+
+func isKeyChar(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
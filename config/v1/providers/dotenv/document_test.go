@@ -0,0 +1,190 @@
+package dotenv_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/raf555/salome/config/v1/providers/dotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+const document = `# Database
+DB_HOST=localhost # the primary
+export DB_PORT = 5432
+
+  # indented comment
+DB_PASSWORD='p@ss word'
+DB_DSN="postgres://user@host/db?sslmode=disable" # quoted
+MULTILINE="line 1
+line 2"
+LITERAL='a\nb'
+ESCAPED="a\nb \"quoted\" \\ \$HOME"
+EMPTY=
+YAML: style
+`
+
+func TestDocumentRoundTrip(t *testing.T) {
+	for name, src := range map[string]string{
+		"lf":                 document,
+		"crlf":               strings.ReplaceAll(document, "\n", "\r\n"),
+		"no final newline":   strings.TrimSuffix(document, "\n"),
+		"blank lines at end": document + "\n\n",
+		"empty":              "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			doc, err := dotenv.ParseDocument(strings.NewReader(src))
+			assert.NoError(t, err)
+
+			var out bytes.Buffer
+			_, err = doc.WriteTo(&out)
+			assert.NoError(t, err)
+			assert.Equal(t, src, out.String())
+		})
+	}
+}
+
+func TestDocumentValues(t *testing.T) {
+	doc, err := dotenv.ParseDocument(strings.NewReader(document))
+	assert.NoError(t, err)
+
+	expected, err := godotenv.Unmarshal(document)
+	assert.NoError(t, err)
+	// variables aren't expanded by the document
+	expected["ESCAPED"] = "a\nb \"quoted\" \\ $HOME"
+
+	assert.Equal(t, expected, doc.Map())
+	assert.Equal(t, []string{
+		"DB_HOST", "DB_PORT", "DB_PASSWORD", "DB_DSN", "MULTILINE", "LITERAL", "ESCAPED", "EMPTY", "YAML",
+	}, doc.Keys())
+
+	value, ok := doc.Get("MULTILINE")
+	assert.True(t, ok)
+	assert.Equal(t, "line 1\nline 2", value)
+
+	_, ok = doc.Get("MISSING")
+	assert.False(t, ok)
+}
+
+func TestDocumentEdit(t *testing.T) {
+	doc, err := dotenv.ParseDocument(strings.NewReader(document))
+	assert.NoError(t, err)
+
+	doc.Set("DB_HOST", "db.internal")
+	doc.Set("DB_PASSWORD", "n3w pass")
+	doc.Set("DB_DSN", "postgres://other/db")
+	doc.Set("MULTILINE", "single line")
+	assert.True(t, doc.Unset("LITERAL"))
+	assert.False(t, doc.Unset("LITERAL"))
+	doc.Set("NEW_KEY", "value")
+
+	var out bytes.Buffer
+	_, err = doc.WriteTo(&out)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `# Database
+DB_HOST=db.internal # the primary
+export DB_PORT = 5432
+
+  # indented comment
+DB_PASSWORD='n3w pass'
+DB_DSN="postgres://other/db" # quoted
+MULTILINE="single line"
+ESCAPED="a\nb \"quoted\" \\ \$HOME"
+EMPTY=
+YAML: style
+NEW_KEY=value
+`, out.String())
+}
+
+func TestDocumentSetQuoting(t *testing.T) {
+	values := []string{
+		"plain",
+		"with space",
+		" leading space",
+		"hash # not a comment",
+		"it's",
+		`say "hi"`,
+		`"fully quoted"`,
+		"multi\nline",
+		"$NOT_EXPANDED ${NEITHER}",
+		"it's $HOME",
+		`back\slash`,
+		`back\slash with 'quote' and $dollar`,
+		"",
+	}
+
+	for _, current := range []string{"KEY=x", "KEY='x'", `KEY="x"`} {
+		for _, value := range values {
+			doc, err := dotenv.ParseDocument(strings.NewReader(current + "\n"))
+			assert.NoError(t, err)
+
+			doc.Set("KEY", value)
+
+			var out bytes.Buffer
+			_, err = doc.WriteTo(&out)
+			assert.NoError(t, err)
+
+			parsed, err := godotenv.Unmarshal(out.String())
+			assert.NoError(t, err)
+			assert.Equal(t, value, parsed["KEY"], "%s set to %q, written as %s", current, value, out.String())
+
+			reparsed, err := dotenv.ParseDocument(&out)
+			assert.NoError(t, err)
+			got, _ := reparsed.Get("KEY")
+			assert.Equal(t, value, got)
+		}
+	}
+}
+
+func TestDocumentSetTrailingBackslash(t *testing.T) {
+	for _, current := range []string{"WIN=x", "WIN='x'", `WIN="x"`} {
+		doc, err := dotenv.ParseDocument(strings.NewReader(current + "\n"))
+		assert.NoError(t, err)
+
+		doc.Set("WIN", `C:\dir\`)
+
+		var out bytes.Buffer
+		_, err = doc.WriteTo(&out)
+		assert.NoError(t, err)
+		assert.Equal(t, `WIN="C:\\dir\\"`+"\n", out.String())
+
+		reparsed, err := dotenv.ParseDocument(&out)
+		assert.NoError(t, err)
+		got, _ := reparsed.Get("WIN")
+		assert.Equal(t, `C:\dir\`, got)
+	}
+}
+
+func TestDocumentSyntaxError(t *testing.T) {
+	_, err := dotenv.ParseDocument(strings.NewReader("A=1\nB=\"unterminated\n\nC=3\n"))
+	assert.True(t, errors.Is(err, dotenv.ErrSyntax))
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = dotenv.ParseDocument(strings.NewReader("A=1\nnot a statement\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestDocumentWriteFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(filename, []byte("# keep me\nKEY=old\n"), 0600)
+
+	doc, err := dotenv.ReadDocument(filename)
+	assert.NoError(t, err)
+
+	doc.Set("KEY", "new")
+	assert.NoError(t, doc.WriteFile(filename))
+
+	content, _ := os.ReadFile(filename)
+	assert.Equal(t, "# keep me\nKEY=new\n", string(content))
+
+	info, _ := os.Stat(filename)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, _ := os.ReadDir(filepath.Dir(filename))
+	assert.Len(t, entries, 1)
+}
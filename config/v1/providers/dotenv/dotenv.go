@@ -47,6 +47,17 @@ func (c *ConfigProvider) Config(_ context.Context) (map[string]string, error) {
 	return maps.Clone(c.initial), nil
 }
 
+// Precedence returns the .env file, for symmetry with [CascadeProvider.Precedence].
+func (c *ConfigProvider) Precedence() []string {
+	return []string{c.filename}
+}
+
+// Source annotates every key as coming from the .env file, e.g. "dotenv:.env".
+// It implements config.SourceAnnotator.
+func (c *ConfigProvider) Source(_ string) string {
+	return "dotenv:" + c.filename
+}
+
 func (c *ConfigProvider) FetchConfig(_ context.Context) (map[string]string, error) {
 	dotenvCfgs, err := c.readConfig()
 	if err != nil {
@@ -57,7 +68,11 @@ func (c *ConfigProvider) FetchConfig(_ context.Context) (map[string]string, erro
 }
 
 func (c *ConfigProvider) readConfig() (map[string]string, error) {
-	file, err := os.Open(c.filename)
+	return readFile(c.filename)
+}
+
+func readFile(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("os.Open: %w", err)
 	}
@@ -1,9 +1,20 @@
 package infisical
 
-import infisical "github.com/infisical/go-sdk"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	infisical "github.com/infisical/go-sdk"
+)
+
+// tokenEnvName is the environment variable of the access token of [WithTokenAuth].
+const tokenEnvName = "INFISICAL_ACCESS_TOKEN"
 
 type authenticator interface {
-	credentialProvider(auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error)
+	credentialProvider(ctx context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error)
 }
 
 type universalAuth struct {
@@ -14,7 +25,7 @@ type universalAuth struct {
 var _ authenticator = (*universalAuth)(nil)
 
 // credentialProvider implements [authenticator].
-func (u *universalAuth) credentialProvider(auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
+func (u *universalAuth) credentialProvider(_ context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
 	return auth.UniversalAuthLogin(u.clientID, u.clientSecret)
 }
 
@@ -26,6 +37,130 @@ type k8sAuth struct {
 var _ authenticator = (*k8sAuth)(nil)
 
 // credentialProvider implements [authenticator].
-func (k *k8sAuth) credentialProvider(auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
+func (k *k8sAuth) credentialProvider(_ context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
 	return auth.KubernetesAuthLogin(k.identityID, k.tokenPath)
 }
+
+type tokenAuth struct {
+	token string
+}
+
+var _ authenticator = (*tokenAuth)(nil)
+
+// credentialProvider implements [authenticator].
+func (t *tokenAuth) credentialProvider(_ context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
+	token := t.token
+	if token == "" {
+		token = os.Getenv(tokenEnvName)
+	}
+	if token == "" {
+		return infisical.MachineIdentityCredential{}, errors.New("missing access token")
+	}
+
+	auth.SetAccessToken(token)
+	return infisical.MachineIdentityCredential{AccessToken: token}, nil
+}
+
+// TokenSource returns the current token of an identity provider, e.g. the JWT of OIDC Auth.
+// It's called on every login, so a token rotated by the identity provider is picked up when logging in again.
+type TokenSource func(ctx context.Context) (string, error)
+
+// StaticToken returns a [TokenSource] of a token that doesn't rotate.
+func StaticToken(token string) TokenSource {
+	return func(context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// TokenFile returns a [TokenSource] reading the token from the file on every call,
+// e.g. a token projected by Kubernetes and rotated in place.
+func TokenFile(path string) TokenSource {
+	return func(context.Context) (string, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("os.ReadFile: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+}
+
+type oidcAuth struct {
+	identityID string
+	jwt        TokenSource
+}
+
+var _ authenticator = (*oidcAuth)(nil)
+
+// credentialProvider implements [authenticator].
+func (o *oidcAuth) credentialProvider(ctx context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
+	jwt, err := o.jwt(ctx)
+	if err != nil {
+		return infisical.MachineIdentityCredential{}, fmt.Errorf("o.jwt: %w", err)
+	}
+	return auth.OidcAuthLogin(o.identityID, jwt)
+}
+
+type jwtAuth struct {
+	identityID string
+	jwt        TokenSource
+}
+
+var _ authenticator = (*jwtAuth)(nil)
+
+// credentialProvider implements [authenticator].
+func (j *jwtAuth) credentialProvider(ctx context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
+	jwt, err := j.jwt(ctx)
+	if err != nil {
+		return infisical.MachineIdentityCredential{}, fmt.Errorf("j.jwt: %w", err)
+	}
+	return auth.JwtAuthLogin(j.identityID, jwt)
+}
+
+type awsIAMAuth struct {
+	identityID string
+}
+
+var _ authenticator = (*awsIAMAuth)(nil)
+
+// credentialProvider implements [authenticator].
+func (a *awsIAMAuth) credentialProvider(_ context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
+	return auth.AwsIamAuthLogin(a.identityID)
+}
+
+type gcpIDTokenAuth struct {
+	identityID string
+}
+
+var _ authenticator = (*gcpIDTokenAuth)(nil)
+
+// credentialProvider implements [authenticator].
+func (g *gcpIDTokenAuth) credentialProvider(_ context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
+	return auth.GcpIdTokenAuthLogin(g.identityID)
+}
+
+type azureAuth struct {
+	identityID string
+	resource   string
+	clientID   string
+}
+
+var _ authenticator = (*azureAuth)(nil)
+
+// credentialProvider implements [authenticator].
+func (a *azureAuth) credentialProvider(_ context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
+	if a.clientID != "" {
+		auth = auth.WithAzureClientID(a.clientID)
+	}
+	return auth.AzureAuthLogin(a.identityID, a.resource)
+}
+
+// AuthFunc authenticates the client, e.g. by calling one of the login methods of auth or
+// [infisical.AuthInterface.SetAccessToken]. It's called again to log in when the credential is rejected.
+type AuthFunc func(auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error)
+
+var _ authenticator = AuthFunc(nil)
+
+// credentialProvider implements [authenticator].
+func (f AuthFunc) credentialProvider(_ context.Context, auth infisical.AuthInterface) (infisical.MachineIdentityCredential, error) {
+	return f(auth)
+}
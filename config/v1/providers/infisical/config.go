@@ -1,6 +1,11 @@
 package infisical
 
-import infisical "github.com/infisical/go-sdk"
+import (
+	"strings"
+	"unicode"
+
+	infisical "github.com/infisical/go-sdk"
+)
 
 type Config struct {
 	SiteUrl      string
@@ -17,4 +22,51 @@ type SecretConfig struct {
 	ProjectSlug string
 	Environment string
 	ConfigPath  string
+
+	// Recursive also lists the secrets of the folders under ConfigPath. Their keys are mapped with KeyFunc,
+	// and a key defined in several folders is taken from the deepest one.
+	Recursive bool
+	// KeyFunc maps the key of a secret to a config key, given the path of its folder relative to the listed path,
+	// "" for the listed path itself. [PathKey] is used if nil.
+	KeyFunc func(relPath, key string) string
+
+	// IncludeImports includes the secrets imported into the listed paths. The secrets of the path take precedence.
+	IncludeImports bool
+	// ExpandReferences expands the ${...} secret references in the values.
+	ExpandReferences bool
+	// Tags keeps only the secrets with at least one of the tags, by slug. All secrets are kept if empty.
+	Tags []string
+
+	// Overlays are more paths, possibly in other environments, merged over ConfigPath in order:
+	// a key is taken from the last path defining it.
+	Overlays []SecretSource
+}
+
+// SecretSource is a path of secrets in an environment. An empty Environment is the one of the [SecretConfig].
+type SecretSource struct {
+	Environment string
+	Path        string
+}
+
+// PathKey prefixes the key with the folders of relPath, upper-cased and separated by underscores,
+// e.g. HOST in database/primary is DATABASE_PRIMARY_HOST. Characters other than letters, digits and
+// underscores in folder names are replaced by underscores.
+func PathKey(relPath, key string) string {
+	var b strings.Builder
+	for folder := range strings.SplitSeq(relPath, "/") {
+		if folder == "" {
+			continue
+		}
+
+		for _, r := range strings.ToUpper(folder) {
+			if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				r = '_'
+			}
+			b.WriteRune(r)
+		}
+		b.WriteByte('_')
+	}
+
+	b.WriteString(key)
+	return b.String()
 }
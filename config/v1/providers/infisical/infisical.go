@@ -1,18 +1,35 @@
 package infisical
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"maps"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
 
 	infisical "github.com/infisical/go-sdk"
+	apierrors "github.com/infisical/go-sdk/packages/errors"
 )
 
+var _ io.Closer = (*ConfigProvider)(nil)
+
 type ConfigProvider struct {
 	secretConfig SecretConfig
 	client       infisical.InfisicalClientInterface
 	cancel       context.CancelFunc
 	initial      map[string]string
+
+	authenticator authenticator
+	loginMu       sync.Mutex
+
+	mu      sync.RWMutex
+	sources map[string]string // secret path of every key of the last fetch
 }
 
 func New(config Config) (*ConfigProvider, error) {
@@ -43,18 +60,19 @@ func NewWithOptions(siteURL string, secretCfg SecretConfig, options ...Option) (
 		CacheExpiryInSeconds: 0, // no cache
 	})
 
-	_, err := opts.authenticator.credentialProvider(client.Auth())
-	if err != nil {
-		cancel()
-		return nil, fmt.Errorf("opts.authenticator.credentialProvider: %w", err)
+	provider := ConfigProvider{
+		client:        client,
+		cancel:        cancel,
+		secretConfig:  secretCfg,
+		authenticator: opts.authenticator,
 	}
 
-	provider := ConfigProvider{
-		client:       client,
-		cancel:       cancel,
-		secretConfig: secretCfg,
+	if err := provider.login(context.TODO()); err != nil {
+		cancel()
+		return nil, fmt.Errorf("provider.login: %w", err)
 	}
 
+	var err error
 	provider.initial, err = provider.FetchConfig(context.TODO())
 	if err != nil {
 		cancel()
@@ -64,28 +82,165 @@ func NewWithOptions(siteURL string, secretCfg SecretConfig, options ...Option) (
 	return &provider, nil
 }
 
-func (c *ConfigProvider) Close() {
+// Close stops the background token refresh of the underlying client.
+// It implements [io.Closer] so the provider is released when the owning config manager is closed.
+func (c *ConfigProvider) Close() error {
 	c.cancel()
+	return nil
 }
 
 func (c *ConfigProvider) Config(_ context.Context) (map[string]string, error) {
 	return maps.Clone(c.initial), nil
 }
 
-func (c *ConfigProvider) FetchConfig(_ context.Context) (map[string]string, error) {
-	secrets, err := c.client.Secrets().List(infisical.ListSecretsOptions{
-		ProjectSlug: c.secretConfig.ProjectSlug,
-		Environment: c.secretConfig.Environment,
-		SecretPath:  c.secretConfig.ConfigPath,
-	})
+// Source annotates the key with the secret path its value came from in the last fetch,
+// e.g. "infisical:my-project/prod/app". It implements config.SourceAnnotator.
+func (c *ConfigProvider) Source(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.sources[key]
+}
+
+func (c *ConfigProvider) FetchConfig(ctx context.Context) (map[string]string, error) {
+	out := map[string]string{}
+	sources := map[string]string{}
+
+	for _, src := range c.secretSources() {
+		secrets, err := c.listSecrets(ctx, src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path.Join(src.Environment, src.Path), err)
+		}
+
+		for _, s := range c.resolveSecrets(src, secrets) {
+			out[s.key] = s.value
+			sources[s.key] = "infisical:" + path.Join(c.secretConfig.ProjectSlug, s.environment, s.path)
+		}
+	}
+
+	c.mu.Lock()
+	c.sources = sources
+	c.mu.Unlock()
+
+	return out, nil
+}
+
+// secretSources returns the config path and the overlays, in increasing precedence, with their environment.
+func (c *ConfigProvider) secretSources() []SecretSource {
+	srcs := append([]SecretSource{{Path: c.secretConfig.ConfigPath}}, c.secretConfig.Overlays...)
+	for i := range srcs {
+		if srcs[i].Environment == "" {
+			srcs[i].Environment = c.secretConfig.Environment
+		}
+	}
+	return srcs
+}
+
+// listSecrets lists the secrets of the source, logging in again if the token is rejected.
+func (c *ConfigProvider) listSecrets(ctx context.Context, src SecretSource) ([]infisical.Secret, error) {
+	opts := infisical.ListSecretsOptions{
+		ProjectSlug:            c.secretConfig.ProjectSlug,
+		Environment:            src.Environment,
+		SecretPath:             src.Path,
+		Recursive:              c.secretConfig.Recursive,
+		IncludeImports:         c.secretConfig.IncludeImports,
+		ExpandSecretReferences: c.secretConfig.ExpandReferences,
+		// keys are made unique by resolveSecrets, including the path of their folder
+		SkipUniqueValidation: true,
+	}
+
+	secrets, err := c.client.Secrets().List(opts)
+	if isUnauthorized(err) {
+		// the token was rejected and the client couldn't refresh it, e.g. its max TTL was reached,
+		// so log in again with the credentials
+		if err := c.login(ctx); err != nil {
+			return nil, fmt.Errorf("c.login: %w", err)
+		}
+		secrets, err = c.client.Secrets().List(opts)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("c.client.Secrets.List: %w", err)
 	}
 
-	out := make(map[string]string, len(secrets))
+	return secrets, nil
+}
+
+type resolvedSecret struct {
+	key         string
+	value       string
+	environment string
+	path        string
+	depth       int
+}
+
+// resolveSecrets filters the secrets of the source by tag and maps their keys, ordered so that
+// the secrets of deeper folders come last and take precedence.
+func (c *ConfigProvider) resolveSecrets(src SecretSource, secrets []infisical.Secret) []resolvedSecret {
+	keyFunc := c.secretConfig.KeyFunc
+	if keyFunc == nil {
+		keyFunc = PathKey
+	}
+	base := path.Clean("/" + src.Path)
+
+	out := make([]resolvedSecret, 0, len(secrets))
 	for _, secret := range secrets {
-		out[secret.SecretKey] = secret.SecretValue
+		if !hasTag(secret, c.secretConfig.Tags) {
+			continue
+		}
+
+		s := resolvedSecret{
+			key:         secret.SecretKey,
+			value:       secret.SecretValue,
+			environment: cmp.Or(secret.Environment, src.Environment),
+			path:        base,
+		}
+
+		if secret.SecretPath != "" {
+			s.path = path.Clean("/" + secret.SecretPath)
+		}
+
+		// secrets outside of the listed path, e.g. imported ones, keep their key
+		relPath, ok := strings.CutPrefix(s.path, strings.TrimSuffix(base, "/")+"/")
+		if c.secretConfig.Recursive && ok && relPath != "" {
+			s.key = keyFunc(relPath, secret.SecretKey)
+			s.depth = strings.Count(relPath, "/") + 1
+		}
+
+		out = append(out, s)
 	}
 
-	return out, nil
+	slices.SortStableFunc(out, func(a, b resolvedSecret) int {
+		return cmp.Compare(a.depth, b.depth)
+	})
+	return out
+}
+
+// hasTag reports whether the secret has at least one of the tags, or the tags are empty.
+func hasTag(secret infisical.Secret, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	for _, tag := range secret.Tags {
+		if slices.Contains(tags, tag.Slug) {
+			return true
+		}
+	}
+	return false
+}
+
+// login authenticates the client with the credentials of the authenticator.
+func (c *ConfigProvider) login(ctx context.Context) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if _, err := c.authenticator.credentialProvider(ctx, c.client.Auth()); err != nil {
+		return fmt.Errorf("c.authenticator.credentialProvider: %w", err)
+	}
+	return nil
+}
+
+func isUnauthorized(err error) bool {
+	var apiErr *apierrors.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
 }
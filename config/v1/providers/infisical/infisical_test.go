@@ -1,3 +1,600 @@
-package infisical
+package infisical_test
 
-// can't be tested for now.
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	infisicalsdk "github.com/infisical/go-sdk"
+	"github.com/raf555/salome/config/v1/providers/infisical"
+	"github.com/stretchr/testify/assert"
+)
+
+// server stands in for the Infisical API and, as an HTTP proxy, for the GCP and Azure metadata servers,
+// whose URLs are hardcoded in the SDK.
+var (
+	server  = &standIn{}
+	siteURL string
+)
+
+func TestMain(m *testing.M) {
+	srv := httptest.NewServer(server)
+	siteURL = srv.URL
+
+	// the proxy is read once per process, and requests to the loopback API bypass it
+	os.Setenv("HTTP_PROXY", srv.URL)
+	code := m.Run()
+
+	srv.Close()
+	os.Exit(code)
+}
+
+type standIn struct {
+	mu     sync.Mutex
+	token  string                      // the accepted access token
+	issued int                         // tokens issued by logins
+	logins map[string][]map[string]any // request bodies by login path
+
+	// folders are the secrets by environment and path, e.g. "prod:/app". Without folders,
+	// every path has the secrets ENVIRONMENT and TOKEN, echoing the request.
+	folders map[string][]secret
+	// imports are the folders imported by environment and path.
+	imports map[string][]string
+}
+
+type secret struct {
+	key   string
+	value string
+	tags  []string
+}
+
+func (s *standIn) reset(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = token
+	s.issued = 0
+	s.logins = map[string][]map[string]any{}
+	s.folders = nil
+	s.imports = nil
+}
+
+func (s *standIn) setFolders(folders map[string][]secret, imports map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.folders = folders
+	s.imports = imports
+}
+
+// revoke rejects the current access token, as when its max TTL is reached.
+func (s *standIn) revoke() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = "revoked"
+}
+
+func (s *standIn) loginBodies(path string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.logins[path]
+}
+
+func (s *standIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Host == "metadata.google.internal":
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, "gcp-jwt-"+r.URL.Query().Get("audience"))
+
+	case r.Host == "169.254.169.254":
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token": "azure-jwt-" + r.URL.Query().Get("resource") + "-" + r.URL.Query().Get("client_id"),
+		})
+
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v1/auth/"):
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.logins[r.URL.Path] = append(s.logins[r.URL.Path], body)
+
+		s.issued++
+		s.token = fmt.Sprintf("token-%d", s.issued)
+		writeJSON(w, http.StatusOK, map[string]any{
+			"accessToken":       s.token,
+			"expiresIn":         3600,
+			"accessTokenMaxTTL": 7200,
+			"tokenType":         "Bearer",
+		})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v3/secrets/raw":
+		if r.Header.Get("Authorization") != "Bearer "+s.token {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"message": "Token expired"})
+			return
+		}
+		s.listSecrets(w, r.URL.Query())
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *standIn) listSecrets(w http.ResponseWriter, query url.Values) {
+	env, secretPath := query.Get("environment"), query.Get("secretPath")
+	if s.folders == nil {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"secrets": []map[string]any{
+				{"secretKey": "ENVIRONMENT", "secretValue": env},
+				{"secretKey": "TOKEN", "secretValue": s.token},
+			},
+			"imports": []any{},
+		})
+		return
+	}
+
+	expand := query.Get("expandSecretReferences") == "true"
+	folderSecrets := func(folder string) []map[string]any {
+		env, folderPath, _ := strings.Cut(folder, ":")
+		out := []map[string]any{}
+		for _, sec := range s.folders[folder] {
+			value := sec.value
+			if expand {
+				// only references to the same folder are supported
+				value = os.Expand(value, func(key string) string {
+					for _, ref := range s.folders[folder] {
+						if ref.key == key {
+							return ref.value
+						}
+					}
+					return ""
+				})
+			}
+
+			tags := []map[string]any{}
+			for _, tag := range sec.tags {
+				tags = append(tags, map[string]any{"slug": tag, "name": tag})
+			}
+			out = append(out, map[string]any{
+				"secretKey":   sec.key,
+				"secretValue": value,
+				"secretPath":  folderPath,
+				"environment": env,
+				"tags":        tags,
+			})
+		}
+		return out
+	}
+
+	folder := env + ":" + secretPath
+	secrets := folderSecrets(folder)
+	if query.Get("recursive") == "true" {
+		for _, sub := range slices.Sorted(maps.Keys(s.folders)) {
+			if strings.HasPrefix(sub, strings.TrimSuffix(folder, "/")+"/") {
+				secrets = append(secrets, folderSecrets(sub)...)
+			}
+		}
+	}
+
+	imports := []map[string]any{}
+	if query.Get("include_imports") == "true" {
+		for _, imported := range s.imports[folder] {
+			env, importPath, _ := strings.Cut(imported, ":")
+			imports = append(imports, map[string]any{
+				"secretPath":  importPath,
+				"environment": env,
+				"secrets":     folderSecrets(imported),
+			})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"secrets": secrets, "imports": imports})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newProvider(t *testing.T, opts ...infisical.Option) (*infisical.ConfigProvider, error) {
+	t.Helper()
+
+	provider, err := infisical.NewWithOptions(siteURL, infisical.SecretConfig{
+		ProjectSlug: "project",
+		Environment: "prod",
+		ConfigPath:  "/",
+	}, opts...)
+	if err == nil {
+		t.Cleanup(func() { _ = provider.Close() })
+	}
+	return provider, err
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T)
+		opt       infisical.Option
+		loginPath string
+		wantBody  map[string]any
+	}{
+		{
+			name:      "universal",
+			opt:       infisical.WithUniversalAuth("client-id", "client-secret"),
+			loginPath: "/api/v1/auth/universal-auth/login",
+			wantBody:  map[string]any{"clientId": "client-id", "clientSecret": "client-secret"},
+		},
+		{
+			name:      "oidc",
+			opt:       infisical.WithOIDCAuth("identity", infisical.StaticToken("oidc-jwt")),
+			loginPath: "/api/v1/auth/oidc-auth/login",
+			wantBody:  map[string]any{"identityId": "identity", "jwt": "oidc-jwt"},
+		},
+		{
+			name: "oidc identity from env",
+			setup: func(t *testing.T) {
+				t.Setenv("INFISICAL_OIDC_AUTH_IDENTITY_ID", "env-identity")
+			},
+			opt:       infisical.WithOIDCAuth("", infisical.StaticToken("oidc-jwt")),
+			loginPath: "/api/v1/auth/oidc-auth/login",
+			wantBody:  map[string]any{"identityId": "env-identity", "jwt": "oidc-jwt"},
+		},
+		{
+			name:      "jwt",
+			opt:       infisical.WithJWTAuth("identity", infisical.StaticToken("signed-jwt")),
+			loginPath: "/api/v1/auth/jwt-auth/login",
+			wantBody:  map[string]any{"identityId": "identity", "jwt": "signed-jwt"},
+		},
+		{
+			name:      "gcp id token",
+			opt:       infisical.WithGCPIDTokenAuth("identity"),
+			loginPath: "/api/v1/auth/gcp-auth/login",
+			wantBody:  map[string]any{"identityId": "identity", "jwt": "gcp-jwt-identity"},
+		},
+		{
+			name:      "azure",
+			opt:       infisical.WithAzureAuth("identity", "https://vault.azure.net", "client-id"),
+			loginPath: "/api/v1/auth/azure-auth/login",
+			wantBody:  map[string]any{"identityId": "identity", "jwt": "azure-jwt-https://vault.azure.net-client-id"},
+		},
+		{
+			name: "aws iam",
+			setup: func(t *testing.T) {
+				t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+				t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+				t.Setenv("AWS_REGION", "eu-west-1")
+				t.Setenv("AWS_CONFIG_FILE", os.DevNull)
+				t.Setenv("AWS_SHARED_CREDENTIALS_FILE", os.DevNull)
+			},
+			opt:       infisical.WithAWSIAMAuth("identity"),
+			loginPath: "/api/v1/auth/aws-auth/login",
+			wantBody:  map[string]any{"identityId": "identity", "iamHttpRequestMethod": "POST"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.reset("")
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+
+			provider, err := newProvider(t, tt.opt)
+			assert.NoError(t, err)
+
+			cfg, err := provider.Config(t.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"ENVIRONMENT": "prod", "TOKEN": "token-1"}, cfg)
+
+			bodies := server.loginBodies(tt.loginPath)
+			if assert.Len(t, bodies, 1) {
+				for key, want := range tt.wantBody {
+					assert.Equal(t, want, bodies[0][key], key)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	server.reset("static-token")
+
+	provider, err := newProvider(t, infisical.WithTokenAuth("static-token"))
+	assert.NoError(t, err)
+
+	cfg, err := provider.FetchConfig(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "static-token", cfg["TOKEN"])
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("INFISICAL_ACCESS_TOKEN", "static-token")
+
+		_, err := newProvider(t, infisical.WithTokenAuth(""))
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Setenv("INFISICAL_ACCESS_TOKEN", "")
+
+		_, err := newProvider(t, infisical.WithTokenAuth(""))
+		assert.ErrorContains(t, err, "missing access token")
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		_, err := newProvider(t, infisical.WithTokenAuth("other-token"))
+		assert.ErrorContains(t, err, "status-code=401")
+	})
+}
+
+func TestCustomAuth(t *testing.T) {
+	server.reset("custom-token")
+
+	calls := 0
+	provider, err := newProvider(t, infisical.WithCustomAuth(func(auth infisicalsdk.AuthInterface) (infisicalsdk.MachineIdentityCredential, error) {
+		calls++
+		auth.SetAccessToken("custom-token")
+		return infisicalsdk.MachineIdentityCredential{AccessToken: "custom-token"}, nil
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	cfg, err := provider.Config(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "custom-token", cfg["TOKEN"])
+
+	t.Run("error", func(t *testing.T) {
+		_, err := newProvider(t, infisical.WithCustomAuth(func(infisicalsdk.AuthInterface) (infisicalsdk.MachineIdentityCredential, error) {
+			return infisicalsdk.MachineIdentityCredential{}, assert.AnError
+		}))
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestRelogin(t *testing.T) {
+	server.reset("")
+	const loginPath = "/api/v1/auth/universal-auth/login"
+
+	provider, err := newProvider(t, infisical.WithUniversalAuth("client-id", "client-secret"))
+	assert.NoError(t, err)
+	assert.Len(t, server.loginBodies(loginPath), 1)
+
+	server.revoke()
+
+	cfg, err := provider.FetchConfig(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", cfg["TOKEN"])
+	assert.Len(t, server.loginBodies(loginPath), 2)
+
+	// the new token is kept
+	cfg, err = provider.FetchConfig(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", cfg["TOKEN"])
+	assert.Len(t, server.loginBodies(loginPath), 2)
+
+	t.Run("rotated token", func(t *testing.T) {
+		server.reset("")
+		const loginPath = "/api/v1/auth/oidc-auth/login"
+
+		tokenPath := filepath.Join(t.TempDir(), "token")
+		assert.NoError(t, os.WriteFile(tokenPath, []byte("jwt-1\n"), 0o600))
+
+		provider, err := newProvider(t, infisical.WithOIDCAuth("identity", infisical.TokenFile(tokenPath)))
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.WriteFile(tokenPath, []byte("jwt-2\n"), 0o600))
+		server.revoke()
+
+		_, err = provider.FetchConfig(t.Context())
+		assert.NoError(t, err)
+
+		var jwts []any
+		for _, body := range server.loginBodies(loginPath) {
+			jwts = append(jwts, body["jwt"])
+		}
+		assert.Equal(t, []any{"jwt-1", "jwt-2"}, jwts)
+	})
+
+	t.Run("token source error", func(t *testing.T) {
+		server.reset("")
+
+		_, err := newProvider(t, infisical.WithJWTAuth("identity", func(context.Context) (string, error) {
+			return "", assert.AnError
+		}))
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("still unauthorized", func(t *testing.T) {
+		server.reset("valid-token")
+
+		calls := 0
+		provider, err := newProvider(t, infisical.WithCustomAuth(func(auth infisicalsdk.AuthInterface) (infisicalsdk.MachineIdentityCredential, error) {
+			calls++
+			auth.SetAccessToken("valid-token")
+			return infisicalsdk.MachineIdentityCredential{AccessToken: "valid-token"}, nil
+		}))
+		assert.NoError(t, err)
+
+		server.revoke()
+
+		_, err = provider.FetchConfig(t.Context())
+		assert.ErrorContains(t, err, "status-code=401")
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestFetchConfig(t *testing.T) {
+	folders := map[string][]secret{
+		"prod:/": {
+			{key: "NAME", value: "app"},
+			{key: "DATABASE_HOST", value: "root-host"},
+			{key: "URL", value: "https://${NAME}.example.com", tags: []string{"public"}},
+		},
+		"prod:/database": {
+			{key: "HOST", value: "db-host", tags: []string{"public"}},
+			{key: "PASSWORD", value: "secret"},
+		},
+		"prod:/database/read-replica": {
+			{key: "HOST", value: "replica-host"},
+		},
+		"prod:/shared": {
+			{key: "NAME", value: "shared"},
+			{key: "REGION", value: "eu"},
+		},
+		"staging:/": {
+			{key: "NAME", value: "staging-app"},
+		},
+		"prod:/overrides": {
+			{key: "REGION", value: "us"},
+		},
+	}
+	imports := map[string][]string{
+		"prod:/": {"prod:/shared"},
+	}
+
+	tests := []struct {
+		name        string
+		cfg         infisical.SecretConfig
+		want        map[string]string
+		wantSources map[string]string
+	}{
+		{
+			name: "path",
+			cfg:  infisical.SecretConfig{},
+			want: map[string]string{
+				"NAME":          "app",
+				"DATABASE_HOST": "root-host",
+				"URL":           "https://${NAME}.example.com",
+			},
+			wantSources: map[string]string{"NAME": "infisical:project/prod"},
+		},
+		{
+			name: "recursive",
+			cfg:  infisical.SecretConfig{Recursive: true},
+			want: map[string]string{
+				"NAME":                       "app",
+				"DATABASE_HOST":              "db-host",
+				"DATABASE_PASSWORD":          "secret",
+				"DATABASE_READ_REPLICA_HOST": "replica-host",
+				"OVERRIDES_REGION":           "us",
+				"SHARED_NAME":                "shared",
+				"SHARED_REGION":              "eu",
+				"URL":                        "https://${NAME}.example.com",
+			},
+			wantSources: map[string]string{
+				"DATABASE_HOST":              "infisical:project/prod/database",
+				"DATABASE_READ_REPLICA_HOST": "infisical:project/prod/database/read-replica",
+			},
+		},
+		{
+			name: "recursive key func",
+			cfg: infisical.SecretConfig{
+				ConfigPath: "/database",
+				Recursive:  true,
+				KeyFunc: func(relPath, key string) string {
+					return strings.ReplaceAll(relPath, "/", ".") + "." + key
+				},
+			},
+			want: map[string]string{
+				"HOST":              "db-host",
+				"PASSWORD":          "secret",
+				"read-replica.HOST": "replica-host",
+			},
+		},
+		{
+			name: "imports",
+			cfg:  infisical.SecretConfig{IncludeImports: true},
+			want: map[string]string{
+				"NAME":          "app",
+				"DATABASE_HOST": "root-host",
+				"URL":           "https://${NAME}.example.com",
+				"REGION":        "eu",
+			},
+			wantSources: map[string]string{
+				"NAME":   "infisical:project/prod",
+				"REGION": "infisical:project/prod/shared",
+			},
+		},
+		{
+			name: "expand references",
+			cfg:  infisical.SecretConfig{ExpandReferences: true, Tags: []string{"public"}},
+			want: map[string]string{"URL": "https://app.example.com"},
+		},
+		{
+			name: "tags",
+			cfg:  infisical.SecretConfig{Recursive: true, Tags: []string{"public", "other"}},
+			want: map[string]string{
+				"DATABASE_HOST": "db-host",
+				"URL":           "https://${NAME}.example.com",
+			},
+		},
+		{
+			name: "overlays",
+			cfg: infisical.SecretConfig{
+				ConfigPath: "/shared",
+				Overlays: []infisical.SecretSource{
+					{Environment: "staging", Path: "/"},
+					{Path: "/overrides"},
+				},
+			},
+			want: map[string]string{
+				"NAME":   "staging-app",
+				"REGION": "us",
+			},
+			wantSources: map[string]string{
+				"NAME":   "infisical:project/staging",
+				"REGION": "infisical:project/prod/overrides",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.reset("")
+			server.setFolders(folders, imports)
+
+			tt.cfg.ProjectSlug = "project"
+			tt.cfg.Environment = "prod"
+			provider, err := infisical.NewWithOptions(siteURL, tt.cfg, infisical.WithUniversalAuth("client-id", "client-secret"))
+			assert.NoError(t, err)
+			t.Cleanup(func() { _ = provider.Close() })
+
+			cfg, err := provider.FetchConfig(t.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+
+			for key, want := range tt.wantSources {
+				assert.Equal(t, want, provider.Source(key), key)
+			}
+		})
+	}
+}
+
+func TestPathKey(t *testing.T) {
+	assert.Equal(t, "HOST", infisical.PathKey("", "HOST"))
+	assert.Equal(t, "DATABASE_HOST", infisical.PathKey("database", "HOST"))
+	assert.Equal(t, "DATABASE_READ_REPLICA_HOST", infisical.PathKey("database/read-replica", "HOST"))
+	assert.Equal(t, "V1_API_KEY", infisical.PathKey("/v1.api/", "KEY"))
+}
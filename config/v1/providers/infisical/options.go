@@ -33,6 +33,80 @@ func WithUniversalAuth(clientID, clientSecret string) Option {
 	}
 }
 
+// WithTokenAuth provides auth with an access token, e.g. a token of Token Auth.
+// token is optional. If not provided, it will be fetched from INFISICAL_ACCESS_TOKEN environment variable.
+func WithTokenAuth(token string) Option {
+	return func(o *options) {
+		o.authenticator = &tokenAuth{
+			token: token,
+		}
+	}
+}
+
+// WithOIDCAuth provides auth with OIDC Auth, given the source of the JWT issued by the identity provider,
+// e.g. [TokenFile]. The JWT is taken from the source on every login, as it's usually short-lived.
+// identityID is optional. If not provided, it will be fetched from INFISICAL_OIDC_AUTH_IDENTITY_ID environment variable.
+func WithOIDCAuth(identityID string, jwt TokenSource) Option {
+	return func(o *options) {
+		o.authenticator = &oidcAuth{
+			identityID: identityID,
+			jwt:        jwt,
+		}
+	}
+}
+
+// WithJWTAuth provides auth with JWT Auth, given the source of a JWT verified by the configured keys of the identity.
+// The JWT is taken from the source on every login, as it's usually short-lived.
+func WithJWTAuth(identityID string, jwt TokenSource) Option {
+	return func(o *options) {
+		o.authenticator = &jwtAuth{
+			identityID: identityID,
+			jwt:        jwt,
+		}
+	}
+}
+
+// WithAWSIAMAuth provides auth with AWS IAM Auth, signing the request with the AWS credentials of the environment.
+// identityID is optional. If not provided, it will be fetched from INFISICAL_AWS_IAM_AUTH_IDENTITY_ID environment variable.
+func WithAWSIAMAuth(identityID string) Option {
+	return func(o *options) {
+		o.authenticator = &awsIAMAuth{
+			identityID: identityID,
+		}
+	}
+}
+
+// WithGCPIDTokenAuth provides auth with GCP ID Token Auth, using the ID token of the GCP metadata server.
+// identityID is optional. If not provided, it will be fetched from INFISICAL_GCP_AUTH_IDENTITY_ID environment variable.
+func WithGCPIDTokenAuth(identityID string) Option {
+	return func(o *options) {
+		o.authenticator = &gcpIDTokenAuth{
+			identityID: identityID,
+		}
+	}
+}
+
+// WithAzureAuth provides auth with Azure Auth, using the token of the Azure managed identity.
+// resource is optional, the default of the SDK is used if empty. clientID selects a user-assigned managed identity.
+// identityID and clientID is optional. If not provided, it will be fetched from
+// INFISICAL_AZURE_AUTH_IDENTITY_ID and INFISICAL_AZURE_AUTH_CLIENT_ID environment variables.
+func WithAzureAuth(identityID, resource, clientID string) Option {
+	return func(o *options) {
+		o.authenticator = &azureAuth{
+			identityID: identityID,
+			resource:   resource,
+			clientID:   clientID,
+		}
+	}
+}
+
+// WithCustomAuth provides auth with fn, for the auth methods that have no option.
+func WithCustomAuth(fn AuthFunc) Option {
+	return func(o *options) {
+		o.authenticator = fn
+	}
+}
+
 func WithRetryConfig(cfg infisical.RetryRequestsConfig) Option {
 	return func(o *options) {
 		o.retryConfig = &cfg
@@ -0,0 +1,221 @@
+package infisical
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader is the header of the signature of Infisical webhooks: "t=<unix millis>;<hex HMAC-SHA256 of the body>".
+	SignatureHeader = "X-Infisical-Signature"
+
+	// EventSecretsModified is the event of Infisical webhooks sent when secrets are created, updated or deleted.
+	EventSecretsModified = "secrets.modified"
+
+	// DefaultWebhookTolerance is the default maximum age of a webhook signature.
+	DefaultWebhookTolerance = 5 * time.Minute
+
+	maxWebhookBody = 1 << 20
+)
+
+var (
+	// ErrInvalidSignature is reported when a webhook has a missing, malformed or invalid signature.
+	ErrInvalidSignature = errors.New("infisical: invalid webhook signature")
+	// ErrExpiredSignature is reported when the timestamp of a webhook signature is out of tolerance.
+	ErrExpiredSignature = errors.New("infisical: expired webhook signature")
+)
+
+// Refresher is refreshed when a webhook reports a change of the secrets of the provider,
+// e.g. the *config.Dynamic using the provider.
+type Refresher interface {
+	Refresh(ctx context.Context) (changed bool, err error)
+}
+
+// RefresherFunc adapts a function to a [Refresher], e.g. the ForceRefresh of a *config.Dynamic.
+type RefresherFunc func(ctx context.Context) (changed bool, err error)
+
+// Refresh implements [Refresher].
+func (f RefresherFunc) Refresh(ctx context.Context) (bool, error) {
+	return f(ctx)
+}
+
+// WebhookPayload is the body of Infisical webhooks.
+type WebhookPayload struct {
+	Event   string `json:"event"`
+	Project struct {
+		WorkspaceID string `json:"workspaceId"`
+		ProjectName string `json:"projectName"`
+		Environment string `json:"environment"`
+		SecretPath  string `json:"secretPath"`
+	} `json:"project"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+type webhookOptions struct {
+	projectID string
+	tolerance time.Duration
+}
+
+// WebhookOption configures the handler of [NewWebhookHandler].
+type WebhookOption func(*webhookOptions)
+
+// WithWebhookProjectID only accepts the webhooks of the project, by ID. Webhooks of any project are accepted by default,
+// as the payload doesn't have the slug of the project.
+func WithWebhookProjectID(id string) WebhookOption {
+	return func(o *webhookOptions) {
+		o.projectID = id
+	}
+}
+
+// WithWebhookTolerance sets the maximum age of a webhook signature, [DefaultWebhookTolerance] by default.
+// Older webhooks are rejected to prevent replays.
+func WithWebhookTolerance(tolerance time.Duration) WebhookOption {
+	return func(o *webhookOptions) {
+		o.tolerance = tolerance
+	}
+}
+
+// NewWebhookHandler returns a handler of the Infisical webhooks of the project, signed with secretKey.
+// When secrets of the provider are modified, the refreshers are refreshed immediately, so a long fetch
+// interval still propagates changes in near real time:
+//
+//	mux.Handle("/webhooks/infisical", infisical.NewWebhookHandler(provider, secretKey, []infisical.Refresher{dynamic}))
+//
+// The Refresh of a *config.Dynamic honors its settle period, so with config.WithSettlePeriod a change is
+// only applied by a fetch after the period. Webhooks are sent on every modification, so the settle period
+// still groups edits of several keys. To apply each modification right away, pass its ForceRefresh instead:
+//
+//	infisical.NewWebhookHandler(provider, secretKey, []infisical.Refresher{infisical.RefresherFunc(dynamic.ForceRefresh)})
+//
+// A modification is relevant if it's in a listed path of the provider or, if recursive, under it.
+// Every modification is relevant if imports are included, as they may come from any path.
+//
+// It responds 401 to unsigned, invalid or expired webhooks, 500 if a refresh failed, and 200 otherwise,
+// including to the test webhook and to irrelevant events.
+func NewWebhookHandler(provider *ConfigProvider, secretKey string, refreshers []Refresher, opts ...WebhookOption) http.Handler {
+	o := webhookOptions{
+		tolerance: DefaultWebhookTolerance,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		signedAt, err := verifySignature(r.Header.Get(SignatureHeader), body, secretKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var payload WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		// only the timestamp of the payload is signed
+		if payload.Timestamp != signedAt {
+			http.Error(w, ErrInvalidSignature.Error(), http.StatusUnauthorized)
+			return
+		}
+		if age := time.Since(time.UnixMilli(signedAt)).Abs(); o.tolerance > 0 && age > o.tolerance {
+			http.Error(w, ErrExpiredSignature.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		if payload.Event != EventSecretsModified ||
+			(o.projectID != "" && payload.Project.WorkspaceID != o.projectID) ||
+			!provider.affectedBy(payload.Project.Environment, payload.Project.SecretPath) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		var errs []error
+		for i, refresher := range refreshers {
+			if _, err := refresher.Refresh(r.Context()); err != nil {
+				errs = append(errs, fmt.Errorf("refreshers[%d]: %w", i, err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			// the errors are reported by the refreshers, e.g. to the error callback of Dynamic
+			http.Error(w, "refresh failed", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// verifySignature verifies the signature header of the body, "t=<unix millis>;<hex HMAC-SHA256 of the body>",
+// and returns its timestamp.
+func verifySignature(header string, body []byte, secretKey string) (int64, error) {
+	ts, sig, ok := strings.Cut(header, ";")
+	if !ok || secretKey == "" {
+		return 0, ErrInvalidSignature
+	}
+
+	ts, ok = strings.CutPrefix(ts, "t=")
+	if !ok {
+		return 0, ErrInvalidSignature
+	}
+	millis, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return 0, ErrInvalidSignature
+	}
+
+	return millis, nil
+}
+
+// affectedBy reports whether a modification of the secrets of the environment and path affects the config.
+func (c *ConfigProvider) affectedBy(environment, secretPath string) bool {
+	if c.secretConfig.IncludeImports {
+		return true
+	}
+
+	secretPath = path.Clean("/" + secretPath)
+	for _, src := range c.secretSources() {
+		if src.Environment != environment {
+			continue
+		}
+
+		base := path.Clean("/" + src.Path)
+		if secretPath == base || (c.secretConfig.Recursive && strings.HasPrefix(secretPath, strings.TrimSuffix(base, "/")+"/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+package infisical_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/raf555/salome/config/v1"
+	"github.com/raf555/salome/config/v1/providers/infisical"
+	"github.com/stretchr/testify/assert"
+)
+
+const webhookSecret = "webhook-secret"
+
+// webhook builds a webhook request as Infisical sends it, signed with the secret key at the time.
+func webhook(t *testing.T, secretKey string, at time.Time, event, environment, secretPath string) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{
+		"event": event,
+		"project": map[string]any{
+			"workspaceId": "project-id",
+			"projectName": "project",
+			"environment": environment,
+			"secretPath":  secretPath,
+		},
+		"timestamp": at.UnixMilli(),
+	})
+	assert.NoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write(body)
+
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/infisical", bytes.NewReader(body))
+	r.Header.Set("X-Infisical-Signature", fmt.Sprintf("t=%d;%s", at.UnixMilli(), hex.EncodeToString(mac.Sum(nil))))
+	return r
+}
+
+func newWebhookProvider(t *testing.T, cfg infisical.SecretConfig) *infisical.ConfigProvider {
+	t.Helper()
+
+	server.reset("")
+	server.setFolders(map[string][]secret{
+		"prod:/app": {{key: "NAME", value: "app"}},
+	}, nil)
+
+	cfg.ProjectSlug = "project"
+	cfg.Environment = "prod"
+	cfg.ConfigPath = "/app"
+	provider, err := infisical.NewWithOptions(siteURL, cfg, infisical.WithUniversalAuth("client-id", "client-secret"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = provider.Close() })
+
+	return provider
+}
+
+func TestWebhookHandler(t *testing.T) {
+	provider := newWebhookProvider(t, infisical.SecretConfig{
+		Overlays: []infisical.SecretSource{{Environment: "staging", Path: "/"}},
+	})
+
+	now := time.Now()
+	tests := []struct {
+		name        string
+		req         *http.Request
+		opts        []infisical.WebhookOption
+		wantStatus  int
+		wantRefresh bool
+	}{
+		{
+			name:        "modified path",
+			req:         webhook(t, webhookSecret, now, "secrets.modified", "prod", "/app"),
+			wantStatus:  http.StatusOK,
+			wantRefresh: true,
+		},
+		{
+			name:        "modified overlay",
+			req:         webhook(t, webhookSecret, now, "secrets.modified", "staging", "/"),
+			wantStatus:  http.StatusOK,
+			wantRefresh: true,
+		},
+		{
+			name:       "other path",
+			req:        webhook(t, webhookSecret, now, "secrets.modified", "prod", "/app/database"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "other environment",
+			req:        webhook(t, webhookSecret, now, "secrets.modified", "dev", "/app"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "test event",
+			req:        webhook(t, webhookSecret, now, "test", "prod", "/app"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "project",
+			req:         webhook(t, webhookSecret, now, "secrets.modified", "prod", "/app"),
+			opts:        []infisical.WebhookOption{infisical.WithWebhookProjectID("project-id")},
+			wantStatus:  http.StatusOK,
+			wantRefresh: true,
+		},
+		{
+			name:       "other project",
+			req:        webhook(t, webhookSecret, now, "secrets.modified", "prod", "/app"),
+			opts:       []infisical.WebhookOption{infisical.WithWebhookProjectID("other-id")},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid signature",
+			req:        webhook(t, "other-secret", now, "secrets.modified", "prod", "/app"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "unsigned",
+			req: func() *http.Request {
+				r := webhook(t, webhookSecret, now, "secrets.modified", "prod", "/app")
+				r.Header.Del("X-Infisical-Signature")
+				return r
+			}(),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "unsigned timestamp",
+			req: func() *http.Request {
+				r := webhook(t, webhookSecret, now.Add(-time.Hour), "secrets.modified", "prod", "/app")
+				_, sig, _ := bytes.Cut([]byte(r.Header.Get("X-Infisical-Signature")), []byte(";"))
+				r.Header.Set("X-Infisical-Signature", fmt.Sprintf("t=%d;%s", now.UnixMilli(), sig))
+				return r
+			}(),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "expired",
+			req:        webhook(t, webhookSecret, now.Add(-time.Hour), "secrets.modified", "prod", "/app"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:        "tolerance",
+			req:         webhook(t, webhookSecret, now.Add(-time.Hour), "secrets.modified", "prod", "/app"),
+			opts:        []infisical.WebhookOption{infisical.WithWebhookTolerance(2 * time.Hour)},
+			wantStatus:  http.StatusOK,
+			wantRefresh: true,
+		},
+		{
+			name:       "method",
+			req:        httptest.NewRequest(http.MethodGet, "/webhooks/infisical", nil),
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refreshed := 0
+			refresher := infisical.RefresherFunc(func(context.Context) (bool, error) {
+				refreshed++
+				return true, nil
+			})
+
+			rec := httptest.NewRecorder()
+			infisical.NewWebhookHandler(provider, webhookSecret, []infisical.Refresher{refresher, refresher}, tt.opts...).ServeHTTP(rec, tt.req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantRefresh {
+				assert.Equal(t, 2, refreshed)
+			} else {
+				assert.Zero(t, refreshed)
+			}
+		})
+	}
+
+	t.Run("refresh error", func(t *testing.T) {
+		failing := infisical.RefresherFunc(func(context.Context) (bool, error) {
+			return false, assert.AnError
+		})
+
+		rec := httptest.NewRecorder()
+		infisical.NewWebhookHandler(provider, webhookSecret, []infisical.Refresher{failing}).
+			ServeHTTP(rec, webhook(t, webhookSecret, now, "secrets.modified", "prod", "/app"))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestWebhookHandlerRelevance(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        infisical.SecretConfig
+		secretPath string
+		want       bool
+	}{
+		{name: "path", secretPath: "/app/", want: true},
+		{name: "sub path", secretPath: "/app/database"},
+		{name: "sibling path", secretPath: "/application"},
+		{name: "recursive sub path", cfg: infisical.SecretConfig{Recursive: true}, secretPath: "/app/database", want: true},
+		{name: "recursive sibling path", cfg: infisical.SecretConfig{Recursive: true}, secretPath: "/application"},
+		{name: "imports", cfg: infisical.SecretConfig{IncludeImports: true}, secretPath: "/shared", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newWebhookProvider(t, tt.cfg)
+
+			refreshed := false
+			refresher := infisical.RefresherFunc(func(context.Context) (bool, error) {
+				refreshed = true
+				return true, nil
+			})
+
+			rec := httptest.NewRecorder()
+			infisical.NewWebhookHandler(provider, webhookSecret, []infisical.Refresher{refresher}).
+				ServeHTTP(rec, webhook(t, webhookSecret, time.Now(), "secrets.modified", "prod", tt.secretPath))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, refreshed)
+		})
+	}
+}
+
+func TestWebhookHandlerDynamic(t *testing.T) {
+	type appConfig struct {
+		Name string `env:"NAME"`
+	}
+
+	provider := newWebhookProvider(t, infisical.SecretConfig{})
+
+	dynamic, err := config.NewDynamic(provider, config.WithDynamicFetchInterval(time.Hour))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = dynamic.Close(context.Background()) })
+
+	getter, err := config.LoadDynamicConfigTo[appConfig](dynamic)
+	assert.NoError(t, err)
+	assert.Equal(t, "app", getter.Get().Name)
+
+	server.setFolders(map[string][]secret{
+		"prod:/app": {{key: "NAME", value: "renamed"}},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	infisical.NewWebhookHandler(provider, webhookSecret, []infisical.Refresher{dynamic}).
+		ServeHTTP(rec, webhook(t, webhookSecret, time.Now(), "secrets.modified", "prod", "/app"))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "renamed", getter.Get().Name)
+
+	t.Run("settle period", func(t *testing.T) {
+		provider := newWebhookProvider(t, infisical.SecretConfig{})
+
+		dynamic, err := config.NewDynamic(provider, config.WithDynamicFetchInterval(time.Hour), config.WithSettlePeriod(time.Hour))
+		assert.NoError(t, err)
+		t.Cleanup(func() { _ = dynamic.Close(context.Background()) })
+
+		getter, err := config.LoadDynamicConfigTo[appConfig](dynamic)
+		assert.NoError(t, err)
+
+		server.setFolders(map[string][]secret{
+			"prod:/app": {{key: "NAME", value: "renamed"}},
+		}, nil)
+
+		// the change waits for the settle period
+		rec := httptest.NewRecorder()
+		infisical.NewWebhookHandler(provider, webhookSecret, []infisical.Refresher{dynamic}).
+			ServeHTTP(rec, webhook(t, webhookSecret, time.Now(), "secrets.modified", "prod", "/app"))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "app", getter.Get().Name)
+
+		rec = httptest.NewRecorder()
+		infisical.NewWebhookHandler(provider, webhookSecret, []infisical.Refresher{infisical.RefresherFunc(dynamic.ForceRefresh)}).
+			ServeHTTP(rec, webhook(t, webhookSecret, time.Now(), "secrets.modified", "prod", "/app"))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "renamed", getter.Get().Name)
+	})
+}
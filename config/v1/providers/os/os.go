@@ -22,6 +22,11 @@ func (c *ConfigProvider) Config(ctx context.Context) (map[string]string, error)
 	return maps.Clone(c.initial), nil
 }
 
+// Source annotates every key as coming from the OS environment. It implements config.SourceAnnotator.
+func (c *ConfigProvider) Source(_ string) string {
+	return "os"
+}
+
 func (c *ConfigProvider) FetchConfig(ctx context.Context) (map[string]string, error) {
 	envs := os.Environ()
 
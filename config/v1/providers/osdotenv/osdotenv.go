@@ -6,18 +6,31 @@ import (
 	"fmt"
 	"io/fs"
 	"maps"
+	"slices"
+	"sync"
 
 	"github.com/raf555/salome/config/v1/providers/dotenv"
 	"github.com/raf555/salome/config/v1/providers/os"
 )
 
+// dotenvProvider is either a single .env file or a cascade of them.
+type dotenvProvider interface {
+	FetchConfig(ctx context.Context) (map[string]string, error)
+	Source(key string) string
+	Precedence() []string
+}
+
 // ConfigProvider provides config from both OS and .env file (if present).
 // Config from .env file takes precedence if exists in both places.
 type ConfigProvider struct {
 	osCfgProvider     *os.ConfigProvider
-	dotenvCfgProvider *dotenv.ConfigProvider
+	dotenvCfgProvider dotenvProvider
 
 	initial map[string]string
+
+	mu          sync.RWMutex
+	dotenvKeys  map[string]bool // keys of the last fetch that came from the .env file
+	dotenvFiles []string        // .env files of the last fetch
 }
 
 func New(filename string) (*ConfigProvider, error) {
@@ -26,19 +39,49 @@ func New(filename string) (*ConfigProvider, error) {
 		return nil, fmt.Errorf("dotenv.New: %w", err)
 	}
 
-	osCfgProvider := os.New()
+	provider := &ConfigProvider{
+		osCfgProvider: os.New(),
+	}
+	if dotenvProvider != nil {
+		provider.dotenvCfgProvider = dotenvProvider
+	}
+
+	return provider.init()
+}
+
+// NewCascade is like New, but with the cascade of .env files in dir (see [dotenv.CascadeProvider]).
+// Config from the .env files takes precedence over the OS, as with New.
+func NewCascade(dir string, opts ...dotenv.CascadeOption) (*ConfigProvider, error) {
+	cascade, err := dotenv.NewCascade(dir, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dotenv.NewCascade: %w", err)
+	}
 
 	provider := &ConfigProvider{
-		dotenvCfgProvider: dotenvProvider,
-		osCfgProvider:     osCfgProvider,
+		dotenvCfgProvider: cascade,
+		osCfgProvider:     os.New(),
 	}
 
-	provider.initial, err = provider.FetchConfig(context.TODO())
+	return provider.init()
+}
+
+func (c *ConfigProvider) init() (*ConfigProvider, error) {
+	initial, err := c.FetchConfig(context.TODO())
 	if err != nil {
 		return nil, fmt.Errorf("provider.FetchConfig: %w", err)
 	}
+	c.initial = initial
+
+	return c, nil
+}
 
-	return provider, nil
+// Precedence returns the .env files loaded in the last fetch, in increasing precedence, all of them
+// taking precedence over the OS.
+func (c *ConfigProvider) Precedence() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.dotenvFiles)
 }
 
 func (c *ConfigProvider) Config(ctx context.Context) (map[string]string, error) {
@@ -60,7 +103,33 @@ func (c *ConfigProvider) FetchConfig(ctx context.Context) (map[string]string, er
 		}
 
 		maps.Copy(out, dotEnv)
+
+		var files []string
+		if err == nil {
+			files = c.dotenvCfgProvider.Precedence()
+		}
+
+		c.mu.Lock()
+		c.dotenvKeys = make(map[string]bool, len(dotEnv))
+		for key := range dotEnv {
+			c.dotenvKeys[key] = true
+		}
+		c.dotenvFiles = files
+		c.mu.Unlock()
 	}
 
 	return out, nil
 }
+
+// Source annotates the key with the source of the provider its value came from in the last fetch,
+// i.e. "dotenv:<filename>" or "os". It implements config.SourceAnnotator.
+func (c *ConfigProvider) Source(key string) string {
+	c.mu.RLock()
+	fromDotenv := c.dotenvKeys[key]
+	c.mu.RUnlock()
+
+	if fromDotenv {
+		return c.dotenvCfgProvider.Source(key)
+	}
+	return c.osCfgProvider.Source(key)
+}
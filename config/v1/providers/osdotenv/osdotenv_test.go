@@ -2,6 +2,7 @@ package osdotenv_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/raf555/salome/config/v1/providers/osdotenv"
@@ -149,3 +150,61 @@ func TestEmptyValues(t *testing.T) {
 	assert.Equal(t, "", config["EMPTY_OS_KEY"])
 	assert.Equal(t, "", config["EMPTY_DOTENV_KEY"])
 }
+
+func TestSource(t *testing.T) {
+	os.Setenv("SOURCE_OS_KEY", "os_value")
+	os.Setenv("SOURCE_SHARED_KEY", "os_shared")
+	defer os.Unsetenv("SOURCE_OS_KEY")
+	defer os.Unsetenv("SOURCE_SHARED_KEY")
+
+	testFile := "test_source_osdotenv.env"
+	os.WriteFile(testFile, []byte("SOURCE_SHARED_KEY=dotenv_shared\n"), 0644)
+	defer os.Remove(testFile)
+
+	provider, _ := osdotenv.New(testFile)
+
+	assert.Equal(t, "os", provider.Source("SOURCE_OS_KEY"))
+	assert.Equal(t, "dotenv:"+testFile, provider.Source("SOURCE_SHARED_KEY"))
+
+	// .env file removed, every key comes from OS on the next fetch
+	os.Remove(testFile)
+	_, err := provider.FetchConfig(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "os", provider.Source("SOURCE_SHARED_KEY"))
+}
+
+func TestCascade(t *testing.T) {
+	t.Setenv("CASCADE_OS_KEY", "os_value")
+	t.Setenv("CASCADE_SHARED_KEY", "os_shared")
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("CASCADE_SHARED_KEY=env\nAPP_ENV=test\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".env.test"), []byte("CASCADE_SHARED_KEY=env_test\n"), 0644)
+
+	provider, err := osdotenv.NewCascade(dir)
+	assert.NoError(t, err)
+
+	config, err := provider.Config(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "os_value", config["CASCADE_OS_KEY"])
+	assert.Equal(t, "env_test", config["CASCADE_SHARED_KEY"])
+
+	assert.Equal(t, []string{filepath.Join(dir, ".env"), filepath.Join(dir, ".env.test")}, provider.Precedence())
+	assert.Equal(t, "os", provider.Source("CASCADE_OS_KEY"))
+	assert.Equal(t, "dotenv:"+filepath.Join(dir, ".env.test"), provider.Source("CASCADE_SHARED_KEY"))
+}
+
+func TestPrecedence(t *testing.T) {
+	testFile := "test_precedence_osdotenv.env"
+	os.WriteFile(testFile, []byte("PRECEDENCE_KEY=dotenv\n"), 0644)
+	defer os.Remove(testFile)
+
+	provider, _ := osdotenv.New(testFile)
+	assert.Equal(t, []string{testFile}, provider.Precedence())
+
+	// .env file removed, nothing takes precedence over the OS on the next fetch
+	os.Remove(testFile)
+	_, err := provider.FetchConfig(t.Context())
+	assert.NoError(t, err)
+	assert.Empty(t, provider.Precedence())
+}
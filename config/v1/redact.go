@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces the values of secret fields.
+const redactedValue = "******"
+
+var redactFieldsCache sync.Map // map[reflect.Type][]configField
+
+// RedactedField is a config field with its value, masked if the field is secret.
+type RedactedField struct {
+	Key   string `json:"key"`
+	Field string `json:"field"`
+	// Value is the field value, or "******" if the field is secret.
+	// It is nil if a parent struct pointer is nil.
+	Value any `json:"value"`
+}
+
+// Redacted lists the keys of the config struct v (or pointer to it) with their values,
+// masking fields tagged with `secret:"true"`. Tagging a nested struct masks all of its fields.
+func Redacted(v any) ([]RedactedField, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil, fmt.Errorf("config: can't redact nil")
+	}
+
+	fields, err := redactFields(rv.Type())
+	if err != nil {
+		return nil, fmt.Errorf("redactFields: %w", err)
+	}
+
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("config: can't redact nil %s", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+
+	out := make([]RedactedField, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, RedactedField{
+			Key:   f.Key,
+			Field: f.Name,
+			Value: redactedFieldValue(f, rv),
+		})
+	}
+	return out, nil
+}
+
+// Dump renders the config struct v as KEY=value lines, masking secret fields (see [Redacted]).
+func Dump(v any) string {
+	fields, err := Redacted(v)
+	if err != nil {
+		return fmt.Sprintf("!config.Dump(%v)", err)
+	}
+
+	var sb strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&sb, "%s=%v\n", f.Key, f.Value)
+	}
+	return sb.String()
+}
+
+// LogValue wraps the config struct v so it's logged by [log/slog] as a group of its keys,
+// masking secret fields (see [Redacted]).
+//
+//	logger.Info("config loaded", slog.Any("config", config.LogValue(cfg)))
+func LogValue(v any) slog.LogValuer {
+	return redactedLogValuer{v: v}
+}
+
+type redactedLogValuer struct {
+	v any
+}
+
+// LogValue implements [slog.LogValuer].
+func (r redactedLogValuer) LogValue() slog.Value {
+	fields, err := Redacted(r.v)
+	if err != nil {
+		return slog.StringValue(fmt.Sprintf("!config.LogValue(%v)", err))
+	}
+
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		attrs = append(attrs, slog.Any(f.Key, f.Value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func redactFields(t reflect.Type) ([]configField, error) {
+	if fields, ok := redactFieldsCache.Load(t); ok {
+		return fields.([]configField), nil
+	}
+
+	fields, err := configFields(t, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	redactFieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+func redactedFieldValue(f configField, root reflect.Value) any {
+	if f.Secret {
+		return redactedValue
+	}
+
+	v, ok := f.value(root)
+	if !ok {
+		return nil
+	}
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// redactValue masks the raw config value of f if f is secret.
+func redactValue(f configField, v string) string {
+	if f.Secret && v != "" {
+		return redactedValue
+	}
+	return v
+}
+
+// redactDecodeError replaces the message and the error of a decode failure of a secret field,
+// as the errors of decoders may contain the value, e.g. `strconv.ParseInt: parsing "hunter2"`.
+func redactDecodeError(secret bool, name, msg string, err error) (string, error) {
+	if !secret {
+		return msg, err
+	}
+	return "invalid secret value", fmt.Errorf("%s: %w", name, ErrInvalidSecret)
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type redactTestConfig struct {
+	Name     string `env:"NAME"`
+	Password string `env:"PASSWORD" secret:"true"`
+	Database *struct {
+		Host string `env:"HOST"`
+		User string `env:"USER"`
+	} `env:",prefix=DB_" secret:"true"`
+	Cache *struct {
+		Addr string `env:"ADDR"`
+	} `env:",prefix=CACHE_,noinit"`
+}
+
+func newRedactTestConfig() redactTestConfig {
+	cfg := redactTestConfig{Name: "app", Password: "hunter2"}
+	cfg.Database = &struct {
+		Host string `env:"HOST"`
+		User string `env:"USER"`
+	}{Host: "localhost", User: "admin"}
+	return cfg
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := newRedactTestConfig()
+
+	fields, err := Redacted(&cfg)
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	expected := []RedactedField{
+		{Key: "NAME", Field: "Name", Value: "app"},
+		{Key: "PASSWORD", Field: "Password", Value: "******"},
+		{Key: "DB_HOST", Field: "Database.Host", Value: "******"},
+		{Key: "DB_USER", Field: "Database.User", Value: "******"},
+		{Key: "CACHE_ADDR", Field: "Cache.Addr", Value: nil},
+	}
+	if !reflect.DeepEqual(expected, fields) {
+		t.Errorf("expecting %v, got %v", expected, fields)
+	}
+
+	if _, err := Redacted(nil); err == nil {
+		t.Errorf("expecting error on nil")
+	}
+
+	if _, err := Redacted((*redactTestConfig)(nil)); err == nil {
+		t.Errorf("expecting error on nil pointer")
+	}
+}
+
+func TestDump(t *testing.T) {
+	cfg := newRedactTestConfig()
+
+	expected := "NAME=app\nPASSWORD=******\nDB_HOST=******\nDB_USER=******\nCACHE_ADDR=<nil>\n"
+	if got := Dump(cfg); got != expected {
+		t.Errorf("expecting %q, got %q", expected, got)
+	}
+
+	if got := Dump(nil); !strings.HasPrefix(got, "!config.Dump(") {
+		t.Errorf("expecting error output, got %q", got)
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	cfg := newRedactTestConfig()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("loaded", slog.Any("config", LogValue(&cfg)))
+
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "admin") {
+		t.Errorf("expecting secrets to be masked, got %q", out)
+	}
+	for _, want := range []string{"config.NAME=app", "config.PASSWORD=******", "config.DB_HOST=******"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expecting %q in %q", want, out)
+		}
+	}
+}
@@ -10,3 +10,11 @@ var (
 	validatorOnce sync.Once
 	vl            *validator.Validate
 )
+
+// defaultValidator returns the package-wide validator used when no validator customization is given.
+func defaultValidator() *validator.Validate {
+	validatorOnce.Do(func() {
+		vl = validator.New()
+	})
+	return vl
+}
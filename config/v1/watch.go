@@ -0,0 +1,114 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// RawChange is a change of a raw config key, as read from the provider.
+type RawChange struct {
+	Key string
+	Old string
+	New string
+	// Added and Removed report whether the key has been added or removed,
+	// in which case Old or New respectively is empty.
+	Added   bool
+	Removed bool
+}
+
+// rawWatch is a subscription to raw key changes.
+type rawWatch struct {
+	match func(key string) bool
+	cb    func(RawChange)
+}
+
+// GetRaw returns the value of the key in the latest config read from the provider.
+// Unlike registered configs, the OS environment is not looked up (see [LookupOrder]).
+func (d *Dynamic) GetRaw(key string) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	value, ok := d.currentCfg[key]
+	return value, ok
+}
+
+// WatchKeys calls cb for every change of the given raw keys, without registering a config struct.
+// Callbacks are called synchronously during the update cycle, after the registered configs are updated,
+// so they must not block for too long. The returned function stops the watch.
+func (d *Dynamic) WatchKeys(cb func(RawChange), keys ...string) (stop func()) {
+	return d.watch(func(key string) bool {
+		return slices.Contains(keys, key)
+	}, cb)
+}
+
+// WatchPrefix is like [Dynamic.WatchKeys] but watches every raw key starting with the prefix.
+func (d *Dynamic) WatchPrefix(prefix string, cb func(RawChange)) (stop func()) {
+	return d.watch(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	}, cb)
+}
+
+func (d *Dynamic) watch(match func(key string) bool, cb func(RawChange)) func() {
+	d.watchMu.Lock()
+	defer d.watchMu.Unlock()
+
+	if d.watches == nil {
+		d.watches = make(map[int]rawWatch)
+	}
+
+	id := d.nextWatchID
+	d.nextWatchID++
+	d.watches[id] = rawWatch{match: match, cb: cb}
+
+	return func() {
+		d.watchMu.Lock()
+		defer d.watchMu.Unlock()
+
+		delete(d.watches, id)
+	}
+}
+
+func (d *Dynamic) notifyWatches(changes []RawChange) {
+	d.watchMu.RLock()
+	watches := make([]rawWatch, 0, len(d.watches))
+	for _, id := range slices.Sorted(maps.Keys(d.watches)) {
+		watches = append(watches, d.watches[id])
+	}
+	d.watchMu.RUnlock()
+
+	for _, w := range watches {
+		for _, c := range changes {
+			if w.match(c.Key) {
+				w.cb(c)
+			}
+		}
+	}
+}
+
+// diffRaw lists the changes of the keys from old to new, sorted by key.
+func diffRaw(old, new map[string]string) []RawChange {
+	var changes []RawChange
+
+	for key, newValue := range new {
+		oldValue, ok := old[key]
+		switch {
+		case !ok:
+			changes = append(changes, RawChange{Key: key, New: newValue, Added: true})
+		case oldValue != newValue:
+			changes = append(changes, RawChange{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+
+	for key, oldValue := range old {
+		if _, ok := new[key]; !ok {
+			changes = append(changes, RawChange{Key: key, Old: oldValue, Removed: true})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b RawChange) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return changes
+}
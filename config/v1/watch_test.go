@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestDynamicWatch(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		providerMock := NewMockProvider(ctrl)
+
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{
+			"MAINTENANCE_MODE": "false",
+			"FEATURE_A":        "1",
+			"UNWATCHED":        "x",
+		}, nil)
+		gomock.InOrder(
+			providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+				"MAINTENANCE_MODE": "true",
+				"FEATURE_B":        "2",
+				"UNWATCHED":        "y",
+			}, nil),
+			providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{
+				"MAINTENANCE_MODE": "false",
+			}, nil).AnyTimes(),
+		)
+
+		dynamic, err := NewDynamic(providerMock, WithAuditSink(AuditSinkFunc(func(context.Context, ChangeEvent) {})))
+		if err != nil {
+			t.Fatalf("expecting nil error when initializing dynamic, got %v", err)
+		}
+
+		var (
+			mu              sync.Mutex
+			keyChanges      []RawChange
+			prefixedChanges []RawChange
+		)
+		stop := dynamic.WatchKeys(func(c RawChange) {
+			mu.Lock()
+			keyChanges = append(keyChanges, c)
+			mu.Unlock()
+		}, "MAINTENANCE_MODE")
+		dynamic.WatchPrefix("FEATURE_", func(c RawChange) {
+			mu.Lock()
+			prefixedChanges = append(prefixedChanges, c)
+			mu.Unlock()
+		})
+
+		dynamic.Start()
+		defer dynamic.Close(t.Context())
+
+		time.Sleep(15 * time.Second)
+
+		if value, ok := dynamic.GetRaw("MAINTENANCE_MODE"); !ok || value != "true" {
+			t.Errorf("expecting raw value true, got %q (%v)", value, ok)
+		}
+		if _, ok := dynamic.GetRaw("FEATURE_A"); ok {
+			t.Errorf("expecting removed key to be absent")
+		}
+
+		stop()
+		time.Sleep(10 * time.Second)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		expectedKeys := []RawChange{{Key: "MAINTENANCE_MODE", Old: "false", New: "true"}}
+		if !reflect.DeepEqual(expectedKeys, keyChanges) {
+			t.Errorf("expecting %v, got %v", expectedKeys, keyChanges)
+		}
+
+		expectedPrefixed := []RawChange{
+			{Key: "FEATURE_A", Old: "1", Removed: true},
+			{Key: "FEATURE_B", New: "2", Added: true},
+			{Key: "FEATURE_B", Old: "2", Removed: true},
+		}
+		if !reflect.DeepEqual(expectedPrefixed, prefixedChanges) {
+			t.Errorf("expecting %v, got %v", expectedPrefixed, prefixedChanges)
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/raf555/salome/config/v1"
+)
+
+// Load fetches the config from the provider once and loads it to T.
+func Load[T any](ctx context.Context, provider Provider, opts ...LoaderOption) (T, error) {
+	var dst T
+
+	loader, err := v1.NewLoader(opts...)
+	if err != nil {
+		return dst, fmt.Errorf("v1.NewLoader: %w", err)
+	}
+
+	cfg, err := provider.Fetch(ctx)
+	if err != nil {
+		return dst, fmt.Errorf("provider.Fetch: %w", err)
+	}
+
+	if err := loader.Load(ctx, &dst, cfg); err != nil {
+		var zero T
+		return zero, fmt.Errorf("loader.Load: %w", err)
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+
+	v1 "github.com/raf555/salome/config/v1"
+)
+
+var (
+	// ErrNotRegistered is returned by [Get] when the config type hasn't been registered.
+	ErrNotRegistered = errors.New("config: not registered")
+	// ErrTypeMismatch is returned when a registration doesn't hold the requested type.
+	ErrTypeMismatch = errors.New("config: type mismatch")
+	// ErrClosed is returned when the manager has been closed.
+	ErrClosed = errors.New("config: manager closed")
+)
+
+// Manager fetches config from a provider and keeps registered configs up to date.
+type Manager struct {
+	opts     options
+	provider Provider
+	loader   *v1.Loader
+
+	mu         sync.RWMutex
+	current    map[string]string
+	registry   map[reflect.Type]registration
+	registered []registration // in registration order
+
+	// updateMu serializes the refreshes, so an older fetch is never applied after a newer one.
+	updateMu sync.Mutex
+
+	startOnce sync.Once
+	closeOnce sync.Once
+	closeCh   chan struct{}
+	closeErr  error
+	loopWg    sync.WaitGroup
+}
+
+// registration is a registered config that can be reloaded from a config map.
+type registration interface {
+	reload(ctx context.Context, loader *v1.Loader, cfg map[string]string) error
+}
+
+// New creates a Manager and fetches the initial config from the provider.
+func New(ctx context.Context, provider Provider, opts ...Option) (*Manager, error) {
+	o := options{
+		fetchInterval: 10 * time.Second,
+		fetchTimeout:  5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	loader, err := v1.NewLoader(o.loaderOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("v1.NewLoader: %w", err)
+	}
+
+	cfg, err := provider.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("provider.Fetch: %w", err)
+	}
+
+	return &Manager{
+		opts:     o,
+		provider: provider,
+		loader:   loader,
+		current:  cfg,
+		registry: make(map[reflect.Type]registration),
+		closeCh:  make(chan struct{}),
+	}, nil
+}
+
+// Start begins polling the provider in the background until ctx is done or the manager is closed.
+// Subsequent calls are no-ops.
+func (m *Manager) Start(ctx context.Context) {
+	m.startOnce.Do(func() {
+		m.loopWg.Go(func() {
+			m.poll(ctx)
+		})
+	})
+}
+
+func (m *Manager) poll(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.fetchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.closeCh:
+			return
+		case <-ticker.C:
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, m.opts.fetchTimeout)
+		err := m.Refresh(fetchCtx)
+		cancel()
+
+		if err != nil && m.opts.errHandler != nil {
+			m.opts.errHandler(ctx, fmt.Errorf("m.Refresh: %w", err))
+		}
+	}
+}
+
+// Refresh fetches the config now and reloads the registered configs if it changed.
+// Configs failing to load keep their previous value; their errors are joined.
+func (m *Manager) Refresh(ctx context.Context) error {
+	m.updateMu.Lock()
+	defer m.updateMu.Unlock()
+
+	select {
+	case <-m.closeCh:
+		return ErrClosed
+	default:
+	}
+
+	cfg, err := m.provider.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("m.provider.Fetch: %w", err)
+	}
+
+	m.mu.Lock()
+	if maps.Equal(m.current, cfg) {
+		m.mu.Unlock()
+		return nil
+	}
+	m.current = cfg
+	registered := m.registered
+	m.mu.Unlock()
+
+	var errs []error
+	for _, r := range registered {
+		if err := r.reload(ctx, m.loader, cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops the background polling and waits for it to exit. Unless [WithSharedProvider] is used,
+// the provider is closed as well if it implements [v1.ContextCloser] or [io.Closer].
+// Subsequent calls return the result of the first one.
+func (m *Manager) Close(ctx context.Context) error {
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+		m.loopWg.Wait()
+
+		// wait for an in-flight Refresh
+		m.updateMu.Lock()
+		defer m.updateMu.Unlock()
+
+		if m.opts.sharedProvider {
+			return
+		}
+
+		if err := closeProvider(ctx, m.provider); err != nil {
+			m.closeErr = fmt.Errorf("closeProvider: %w", err)
+		}
+	})
+	return m.closeErr
+}
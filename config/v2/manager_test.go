@@ -0,0 +1,205 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	v1 "github.com/raf555/salome/config/v1"
+)
+
+type mapProvider struct {
+	mu     sync.Mutex
+	cfg    map[string]string
+	err    error
+	closed atomic.Bool
+}
+
+func (p *mapProvider) Fetch(context.Context) (map[string]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	return maps.Clone(p.cfg), nil
+}
+
+func (p *mapProvider) set(key, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg[key] = value
+}
+
+func (p *mapProvider) Close() error {
+	p.closed.Store(true)
+	return nil
+}
+
+type serverConfig struct {
+	Port int `env:"SALOME_V2_PORT" validate:"min=1"`
+}
+
+func TestManager(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		provider := &mapProvider{cfg: map[string]string{"SALOME_V2_PORT": "8080"}}
+
+		var handled atomic.Int32
+		mgr, err := New(t.Context(), provider,
+			WithLoaderOptions(v1.WithLookupOrder(v1.LookupProviderOnly)),
+			WithErrorHandler(func(context.Context, error) {
+				handled.Add(1)
+			}),
+		)
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+
+		value, err := Register[serverConfig](t.Context(), mgr)
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+		if again, _ := Register[serverConfig](t.Context(), mgr); again != value {
+			t.Errorf("expecting registering twice to return the same value")
+		}
+
+		if got := value.Get(); got.Port != 8080 {
+			t.Errorf("expecting port 8080, got %d", got.Port)
+		}
+
+		var (
+			changesMu sync.Mutex
+			changes   []int
+		)
+		value.OnChange(func(_ context.Context, old, new serverConfig) {
+			changesMu.Lock()
+			defer changesMu.Unlock()
+			changes = append(changes, old.Port, new.Port)
+		})
+
+		mgr.Start(t.Context())
+
+		provider.set("SALOME_V2_PORT", "9090")
+		time.Sleep(15 * time.Second)
+
+		got, err := Get[serverConfig](mgr)
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+		if got.Port != 9090 {
+			t.Errorf("expecting port 9090 after refresh, got %d", got.Port)
+		}
+		changesMu.Lock()
+		if len(changes) != 2 || changes[0] != 8080 || changes[1] != 9090 {
+			t.Errorf("expecting a single change from 8080 to 9090, got %v", changes)
+		}
+		changesMu.Unlock()
+
+		// invalid values keep the previous config
+		provider.set("SALOME_V2_PORT", "0")
+		time.Sleep(10 * time.Second)
+
+		if value.Get().Port != 9090 {
+			t.Errorf("expecting previous config to be kept, got %d", value.Get().Port)
+		}
+		if handled.Load() != 1 {
+			t.Errorf("expecting 1 handled error, got %d", handled.Load())
+		}
+
+		if err := mgr.Close(t.Context()); err != nil {
+			t.Errorf("expecting nil error on close, got %v", err)
+		}
+		if !provider.closed.Load() {
+			t.Errorf("expecting provider to be closed")
+		}
+		if err := mgr.Refresh(t.Context()); !errors.Is(err, ErrClosed) {
+			t.Errorf("expecting ErrClosed after close, got %v", err)
+		}
+	})
+}
+
+func TestRefreshSerialized(t *testing.T) {
+	// the first refresh fetches an older config, and is slower than the second one
+	var fetches atomic.Int32
+	fetching, release := make(chan struct{}), make(chan struct{})
+	provider := ProviderFunc(func(ctx context.Context) (map[string]string, error) {
+		switch fetches.Add(1) {
+		case 1:
+			return map[string]string{"SALOME_V2_PORT": "8080"}, nil
+		case 2:
+			close(fetching)
+			<-release
+			return map[string]string{"SALOME_V2_PORT": "8081"}, nil
+		default:
+			return map[string]string{"SALOME_V2_PORT": "8082"}, nil
+		}
+	})
+
+	mgr, err := New(t.Context(), provider, WithLoaderOptions(v1.WithLookupOrder(v1.LookupProviderOnly)))
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+	value, err := Register[serverConfig](t.Context(), mgr)
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Go(func() { _ = mgr.Refresh(t.Context()) })
+	<-fetching
+
+	second := make(chan struct{})
+	wg.Go(func() {
+		defer close(second)
+		_ = mgr.Refresh(t.Context())
+	})
+
+	// the second refresh waits for the first one to be applied
+	select {
+	case <-second:
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	wg.Wait()
+
+	if got := value.Get().Port; got != 8082 {
+		t.Errorf("expecting the newest port 8082, got %d", got)
+	}
+}
+
+func TestGetNotRegistered(t *testing.T) {
+	mgr, err := New(t.Context(), ProviderFunc(func(context.Context) (map[string]string, error) {
+		return map[string]string{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	if _, err := Get[serverConfig](mgr); !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("expecting ErrNotRegistered, got %v", err)
+	}
+}
+
+func TestRegisterInvalid(t *testing.T) {
+	mgr, err := New(t.Context(), ProviderFunc(func(context.Context) (map[string]string, error) {
+		return map[string]string{"SALOME_V2_PORT": "-1"}, nil
+	}), WithLoaderOptions(v1.WithLookupOrder(v1.LookupProviderOnly)))
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+
+	_, err = Register[serverConfig](t.Context(), mgr)
+	var verr *v1.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expecting ValidationError, got %v", err)
+	}
+
+	if _, err := Get[serverConfig](mgr); !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("expecting failed registration not to be registered, got %v", err)
+	}
+}
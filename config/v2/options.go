@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/raf555/salome/config/v1"
+)
+
+// LoaderOption customizes how configs are parsed and validated, see [v1.LoaderOption].
+type LoaderOption = v1.LoaderOption
+
+type options struct {
+	fetchInterval  time.Duration
+	fetchTimeout   time.Duration
+	errHandler     func(ctx context.Context, err error)
+	sharedProvider bool
+	loaderOptions  []LoaderOption
+}
+
+// Option configures a [Manager].
+type Option func(*options)
+
+// WithFetchInterval sets how often the provider is polled for changes. Defaults to 10s.
+func WithFetchInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.fetchInterval = interval
+	}
+}
+
+// WithFetchTimeout sets the timeout of each background fetch. Defaults to 5s.
+func WithFetchTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.fetchTimeout = timeout
+	}
+}
+
+// WithErrorHandler sets the handler of background errors, e.g. fetch or validation failures.
+// It must not block for too long.
+func WithErrorHandler(handler func(ctx context.Context, err error)) Option {
+	return func(o *options) {
+		o.errHandler = handler
+	}
+}
+
+// WithSharedProvider prevents [Manager.Close] from closing the provider.
+func WithSharedProvider() Option {
+	return func(o *options) {
+		o.sharedProvider = true
+	}
+}
+
+// WithLoaderOptions sets the options used to parse and validate every registered config.
+func WithLoaderOptions(opts ...LoaderOption) Option {
+	return func(o *options) {
+		o.loaderOptions = append(o.loaderOptions, opts...)
+	}
+}
@@ -0,0 +1,91 @@
+// Package config is the typed config API. Configs are structs parsed from `env` tags and
+// validated with `validate` tags, like in v1, but registrations, getters and callbacks are
+// generic and every operation doing work takes a context.
+//
+//	mgr, err := config.New(ctx, config.FromV1(dotenvProvider))
+//	...
+//	db, err := config.Register[DatabaseConfig](ctx, mgr)
+//	...
+//	db.OnChange(func(ctx context.Context, old, new DatabaseConfig) { ... })
+//	mgr.Start(ctx)
+//	defer mgr.Close(ctx)
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	v1 "github.com/raf555/salome/config/v1"
+)
+
+// Provider provides the raw key/value config.
+type Provider interface {
+	// Fetch reads the latest config.
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// ProviderFunc adapts a function to [Provider].
+type ProviderFunc func(ctx context.Context) (map[string]string, error)
+
+func (f ProviderFunc) Fetch(ctx context.Context) (map[string]string, error) {
+	return f(ctx)
+}
+
+// FromV1 adapts a v1 provider. The first fetch returns the config initially read by the provider,
+// later fetches read it again. Closing and source annotations are forwarded to the v1 provider.
+func FromV1(provider v1.Provider) Provider {
+	return &v1Provider{provider: provider}
+}
+
+type v1Provider struct {
+	provider v1.Provider
+
+	mu      sync.Mutex
+	fetched bool
+}
+
+func (p *v1Provider) Fetch(ctx context.Context) (map[string]string, error) {
+	p.mu.Lock()
+	initial := !p.fetched
+	p.fetched = true
+	p.mu.Unlock()
+
+	if initial {
+		cfg, err := p.provider.Config(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("p.provider.Config: %w", err)
+		}
+		return cfg, nil
+	}
+
+	cfg, err := p.provider.FetchConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("p.provider.FetchConfig: %w", err)
+	}
+	return cfg, nil
+}
+
+// Close implements [v1.ContextCloser].
+func (p *v1Provider) Close(ctx context.Context) error {
+	return v1.CloseProvider(ctx, p.provider)
+}
+
+// Source implements [v1.SourceAnnotator].
+func (p *v1Provider) Source(key string) string {
+	if a, ok := p.provider.(v1.SourceAnnotator); ok {
+		return a.Source(key)
+	}
+	return ""
+}
+
+// closeProvider closes the provider if it implements [v1.ContextCloser] or [io.Closer].
+func closeProvider(ctx context.Context, provider Provider) error {
+	switch p := provider.(type) {
+	case v1.ContextCloser:
+		return p.Close(ctx)
+	case interface{ Close() error }:
+		return p.Close()
+	}
+	return nil
+}
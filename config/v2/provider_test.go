@@ -0,0 +1,45 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/raf555/salome/config/v1"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFromV1(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	providerMock := v1.NewMockProvider(ctrl)
+
+	gomock.InOrder(
+		providerMock.EXPECT().Config(gomock.Any()).Return(map[string]string{"SALOME_V2_PORT": "1"}, nil),
+		providerMock.EXPECT().FetchConfig(gomock.Any()).Return(map[string]string{"SALOME_V2_PORT": "2"}, nil),
+	)
+
+	provider := FromV1(providerMock)
+
+	for _, expected := range []string{"1", "2"} {
+		cfg, err := provider.Fetch(t.Context())
+		if err != nil {
+			t.Fatalf("expecting nil error, got %v", err)
+		}
+		if cfg["SALOME_V2_PORT"] != expected {
+			t.Errorf("expecting %q, got %q", expected, cfg["SALOME_V2_PORT"])
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	provider := ProviderFunc(func(context.Context) (map[string]string, error) {
+		return map[string]string{"SALOME_V2_PORT": "8080"}, nil
+	})
+
+	cfg, err := Load[serverConfig](t.Context(), provider, v1.WithLookupOrder(v1.LookupProviderOnly))
+	if err != nil {
+		t.Fatalf("expecting nil error, got %v", err)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("expecting port 8080, got %d", cfg.Port)
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	v1 "github.com/raf555/salome/config/v1"
+)
+
+// Value holds the latest version of a registered config of type T.
+type Value[T any] struct {
+	current atomic.Pointer[T]
+
+	mu        sync.Mutex
+	callbacks []func(ctx context.Context, old, new T)
+}
+
+// Register registers the config struct T and loads it from the current config.
+// Registering the same type again returns the existing registration.
+func Register[T any](ctx context.Context, m *Manager) (*Value[T], error) {
+	t := reflect.TypeFor[T]()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r, ok := m.registry[t]; ok {
+		v, ok := r.(*Value[T])
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is registered as %T", ErrTypeMismatch, t, r)
+		}
+		return v, nil
+	}
+
+	v := &Value[T]{}
+	if err := v.reload(ctx, m.loader, m.current); err != nil {
+		return nil, err
+	}
+
+	m.registry[t] = v
+	m.registered = append(m.registered, v)
+
+	return v, nil
+}
+
+// Get returns the latest version of the registered config T.
+func Get[T any](m *Manager) (T, error) {
+	t := reflect.TypeFor[T]()
+
+	m.mu.RLock()
+	r, ok := m.registry[t]
+	m.mu.RUnlock()
+
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %s", ErrNotRegistered, t)
+	}
+
+	v, ok := r.(*Value[T])
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %s is registered as %T", ErrTypeMismatch, t, r)
+	}
+
+	return v.Get(), nil
+}
+
+// Get returns the latest version of the config. It is lock-free.
+func (v *Value[T]) Get() T {
+	return *v.current.Load()
+}
+
+// OnChange registers a callback called with the previous and the new version whenever the config
+// changes. Callbacks are called synchronously during the refresh, so they must not block for too long.
+func (v *Value[T]) OnChange(cb func(ctx context.Context, old, new T)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.callbacks = append(v.callbacks, cb)
+}
+
+func (v *Value[T]) reload(ctx context.Context, loader *v1.Loader, cfg map[string]string) error {
+	var dst T
+	if err := loader.Load(ctx, &dst, cfg); err != nil {
+		return fmt.Errorf("loader.Load(%s): %w", reflect.TypeFor[T](), err)
+	}
+
+	old := v.current.Swap(&dst)
+	if old == nil || v1.Equal(old, &dst) {
+		return nil
+	}
+
+	v.mu.Lock()
+	callbacks := v.callbacks
+	v.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(ctx, *old, dst)
+	}
+
+	return nil
+}
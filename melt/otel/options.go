@@ -14,6 +14,8 @@ type options struct {
 
 	// Sampling. Range [0, 1]. Default 1.0.
 	traceSampleRatio float64
+	// Sampling ratio changed at runtime, takes precedence over traceSampleRatio.
+	traceSampleRatioVar *SampleRatio
 
 	// Metric export.
 	metricExportInterval time.Duration // default 10s
@@ -64,6 +66,16 @@ func WithTraceSampleRatio(ratio float64) Option {
 	}
 }
 
+// WithTraceSampleRatioVar samples traces with a ratio that can be changed at runtime
+// with [SampleRatio.Set], e.g. from a dynamic config. It takes precedence over [WithTraceSampleRatio].
+//
+// Wrapped by ParentBased so child spans honor the upstream sampling decision.
+func WithTraceSampleRatioVar(ratio *SampleRatio) Option {
+	return func(o *options) {
+		o.traceSampleRatioVar = ratio
+	}
+}
+
 // WithMetricExportInterval sets how often the metric reader exports.
 // Defaults to 10s.
 func WithMetricExportInterval(d time.Duration) Option {
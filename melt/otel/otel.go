@@ -68,6 +68,9 @@ func NewOrNoop(ctx context.Context, serviceName string, opts ...Option) (OpenTel
 	}
 
 	sampler := trace.ParentBased(trace.TraceIDRatioBased(cfg.traceSampleRatio))
+	if cfg.traceSampleRatioVar != nil {
+		sampler = trace.ParentBased(cfg.traceSampleRatioVar)
+	}
 
 	tracerProvider := trace.NewTracerProvider(
 		trace.WithResource(res),
@@ -0,0 +1,14 @@
+// Package otelconfig binds the runtime knobs of [otel] to a dynamic config, see [config.Bind].
+// It's separate from [otel] and [config], so neither depends on the other.
+package otelconfig
+
+import (
+	config "github.com/raf555/salome/config/v1"
+	"github.com/raf555/salome/melt/otel"
+)
+
+// BindTraceSampleRatio keeps ratio in sync with the trace sampling ratio of the config.
+// The ratio must be given to [otel.NewOrNoop] with [otel.WithTraceSampleRatioVar].
+func BindTraceSampleRatio[T any](getter config.DynamicConfigGetterWithNotify[T], ratio *otel.SampleRatio, selector func(T) float64) {
+	config.Bind(getter, selector, ratio.Set)
+}
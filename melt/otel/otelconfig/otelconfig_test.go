@@ -0,0 +1,47 @@
+package otelconfig
+
+import (
+	"math"
+	"testing"
+
+	config "github.com/raf555/salome/config/v1"
+	"github.com/raf555/salome/melt/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBindTraceSampleRatio(t *testing.T) {
+	type Config struct {
+		SampleRatio float64
+	}
+
+	ctrl := gomock.NewController(t)
+	getter := config.NewMockDynamicConfigGetterWithNotify[Config](ctrl)
+
+	var callback func(Config)
+	getter.EXPECT().RegisterCallback(gomock.Any()).Do(func(cb func(Config)) {
+		callback = cb
+	})
+	getter.EXPECT().Get().Return(Config{SampleRatio: 0.5})
+
+	// the zero value samples every trace until bound
+	var ratio otel.SampleRatio
+	if res := ratio.ShouldSample(trace.SamplingParameters{}); ratio.Ratio() != 1 || res.Decision != trace.RecordAndSample {
+		t.Errorf("expecting the zero value to sample every trace, got ratio %v and %v", ratio.Ratio(), res.Decision)
+	}
+
+	BindTraceSampleRatio(getter, &ratio, func(c Config) float64 { return c.SampleRatio })
+	if ratio.Ratio() != 0.5 {
+		t.Errorf("expecting initial ratio 0.5, got %v", ratio.Ratio())
+	}
+
+	callback(Config{SampleRatio: 0.25})
+	if ratio.Ratio() != 0.25 {
+		t.Errorf("expecting updated ratio 0.25, got %v", ratio.Ratio())
+	}
+
+	callback(Config{SampleRatio: math.NaN()})
+	if ratio.Ratio() != 0.25 {
+		t.Errorf("expecting NaN to be ignored, got %v", ratio.Ratio())
+	}
+}
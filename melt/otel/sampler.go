@@ -0,0 +1,66 @@
+package otel
+
+import (
+	"math"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SampleRatio is a head-based trace sampling ratio that can be changed at runtime,
+// like [log/slog.LevelVar] for log levels. It implements [trace.Sampler].
+// The zero value samples every trace, i.e. has a ratio of 1.
+type SampleRatio struct {
+	ratio   atomic.Uint64 // math.Float64bits of the ratio
+	sampler atomic.Pointer[trace.Sampler]
+}
+
+var _ trace.Sampler = (*SampleRatio)(nil)
+
+// NewSampleRatio creates a SampleRatio with the given initial ratio.
+func NewSampleRatio(ratio float64) *SampleRatio {
+	r := &SampleRatio{}
+	r.Set(ratio)
+	return r
+}
+
+// Set changes the ratio. Values outside [0, 1] are clamped, and NaN is ignored.
+func (r *SampleRatio) Set(ratio float64) {
+	if math.IsNaN(ratio) {
+		return
+	}
+	ratio = min(max(ratio, 0), 1)
+
+	// the ratio is stored first, so it's set once the sampler is
+	r.ratio.Store(math.Float64bits(ratio))
+	sampler := trace.TraceIDRatioBased(ratio)
+	r.sampler.Store(&sampler)
+}
+
+// Ratio returns the current ratio.
+func (r *SampleRatio) Ratio() float64 {
+	if r.sampler.Load() == nil {
+		return 1
+	}
+	return math.Float64frombits(r.ratio.Load())
+}
+
+// ShouldSample implements [trace.Sampler].
+func (r *SampleRatio) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return r.load().ShouldSample(p)
+}
+
+// Description implements [trace.Sampler].
+func (r *SampleRatio) Description() string {
+	return r.load().Description()
+}
+
+// load returns the current sampler, sampling every trace if the ratio was never set.
+func (r *SampleRatio) load() trace.Sampler {
+	if sampler := r.sampler.Load(); sampler != nil {
+		return *sampler
+	}
+	return alwaysSample
+}
+
+var alwaysSample = trace.TraceIDRatioBased(1)